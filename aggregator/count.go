@@ -35,6 +35,9 @@ func Count(ctx context.Context, queryer vquery.Queryer, q vparam.Queryer) (numbe
 	ok, err = vrow.QueryOne(queryer, ctx, q, func(ro vrows.Rower) (err error) {
 		return ro.Scan(&number)
 	})
+	if err != nil {
+		return 0, err
+	}
 	if !ok {
 		return 0, sql.ErrNoRows
 	}
@@ -17,6 +17,7 @@ package aggregator
 
 import (
 	"context"
+	"errors"
 	"github.com/stretchr/testify/mock"
 	"github.com/wojnosystems/vsql/ulong"
 	"github.com/wojnosystems/vsql/vquery"
@@ -61,3 +62,32 @@ func TestCount(t *testing.T) {
 	rowsMock.AssertExpectations(t)
 	rowMock.AssertExpectations(t)
 }
+
+func TestCount_ErrorIterating(t *testing.T) {
+	errExpected := errors.New("connection lost")
+
+	rowsMock := &vrows.RowserMock{}
+	rowsMock.On("Next").
+		Once().
+		Return(nil)
+	rowsMock.On("Err").
+		Once().
+		Return(errExpected)
+	rowsMock.On("Close").
+		Once().
+		Return(nil)
+
+	queryerMock := &vquery.QueryerMock{}
+	queryerMock.On("Query", mock.Anything, mock.Anything).
+		Once().
+		Return(rowsMock, nil)
+
+	_, err := Count(context.Background(),
+		queryerMock, nil)
+	if err != errExpected {
+		t.Errorf("Expected the iteration error, but got %v", err)
+	}
+
+	queryerMock.AssertExpectations(t)
+	rowsMock.AssertExpectations(t)
+}
@@ -0,0 +1,92 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package export
+
+import (
+	"encoding/csv"
+	"github.com/wojnosystems/vsql/vrow"
+	"github.com/wojnosystems/vsql/vrows"
+	"io"
+)
+
+// CSVOptions configures CSVWith
+type CSVOptions struct {
+	// Delimiter separates the fields. Defaults to a comma if 0
+	Delimiter rune
+	// Header writes the column names as the first line
+	Header bool
+	// Columns are the names written as the header when there are no vrows to take them from. If nil, an empty result is an empty file
+	Columns []string
+	// Null is written for NULL values
+	Null string
+	// UseCRLF ends lines with \r\n instead of \n
+	UseCRLF bool
+}
+
+// DefaultCSVOptions are used by CSV: comma separated with a header and NULLs as empty fields
+var DefaultCSVOptions = CSVOptions{
+	Delimiter: ',',
+	Header:    true,
+}
+
+// CSV writes every vrow to w as comma separated values with a header. See CSVWith
+func CSV(w io.Writer, r vrows.Rowser) (err error) {
+	return CSVWith(w, r, DefaultCSVOptions)
+}
+
+// CSVWith writes every vrow to w as delimiter separated values, one line per vrow, as they are read
+//
+// The header comes from the columns of the first vrow. If there are no vrows, opts.Columns is the header, and if that is nil too, nothing is written.
+// Values are formatted as described by FormatValue
+// r is closed when CSVWith returns
+// @param w is where the CSV is written
+// @param r comes from Query() calls
+// @param opts configure the format
+// @return err the database error encountered, or the first error writing to w
+func CSVWith(w io.Writer, r vrows.Rowser, opts CSVOptions) (err error) {
+	cw := csv.NewWriter(w)
+	if opts.Delimiter != 0 {
+		cw.Comma = opts.Delimiter
+	}
+	cw.UseCRLF = opts.UseCRLF
+	var record []string
+	err = vrow.EachValues(r, func(columns []string, values []interface{}) (stop bool, err error) {
+		if record == nil {
+			if opts.Header {
+				if err = cw.Write(columns); err != nil {
+					return true, err
+				}
+			}
+			record = make([]string, len(columns))
+		}
+		for i, value := range values {
+			if value == nil {
+				record[i] = opts.Null
+			} else {
+				record[i] = FormatValue(value)
+			}
+		}
+		return false, cw.Write(record)
+	})
+	if err == nil && record == nil && opts.Header && opts.Columns != nil {
+		err = cw.Write(opts.Columns)
+	}
+	cw.Flush()
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,98 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package export
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/wojnosystems/vsql/vrows"
+	"testing"
+	"time"
+)
+
+var testColumns = []string{"id", "name", "avatar", "joined_at"}
+
+// newTestRowsMock creates a Rowser with testColumns that returns a vrow for each set of values
+func newTestRowsMock(rows ...[]interface{}) *vrows.RowserMock {
+	columnTypes := vrows.NewColumnTyperMocks("BIGINT", "VARCHAR", "BLOB", "DATETIME")
+	rowMocks := make([]vrows.Rower, len(rows))
+	for i, values := range rows {
+		rowMock := vrows.NewRowerMock(testColumns, values...)
+		rowMock.On("ColumnTypes").
+			Return(columnTypes, nil)
+		rowMocks[i] = rowMock
+	}
+	return vrows.NewRowserMock(rowMocks...)
+}
+
+var testJoinedAt = time.Date(2019, 7, 4, 12, 30, 0, 0, time.UTC)
+
+func newTestRows() *vrows.RowserMock {
+	return newTestRowsMock(
+		[]interface{}{int64(1), []byte("bob, jr."), []byte{0xff}, testJoinedAt},
+		[]interface{}{int64(2), []byte(`say "hi"`), nil, nil},
+	)
+}
+
+func TestCSV(t *testing.T) {
+	cases := map[string]struct {
+		rows     *vrows.RowserMock
+		opts     CSVOptions
+		expected string
+	}{
+		"default": {
+			rows: newTestRows(),
+			opts: DefaultCSVOptions,
+			expected: "id,name,avatar,joined_at\n" +
+				"1,\"bob, jr.\",/w==,2019-07-04T12:30:00Z\n" +
+				"2,\"say \"\"hi\"\"\",,\n",
+		},
+		"tabs without header": {
+			rows: newTestRows(),
+			opts: CSVOptions{Delimiter: '\t', Null: `\N`},
+			expected: "1\tbob, jr.\t/w==\t2019-07-04T12:30:00Z\n" +
+				"2\t\"say \"\"hi\"\"\"\t\\N\t\\N\n",
+		},
+		"no rows": {
+			rows:     newTestRowsMock(),
+			opts:     DefaultCSVOptions,
+			expected: "",
+		},
+		"no rows with columns": {
+			rows:     newTestRowsMock(),
+			opts:     CSVOptions{Delimiter: ',', Header: true, Columns: testColumns},
+			expected: "id,name,avatar,joined_at\n",
+		},
+		"no rows with columns without header": {
+			rows:     newTestRowsMock(),
+			opts:     CSVOptions{Delimiter: ',', Columns: testColumns},
+			expected: "",
+		},
+		"columns are only used without rows": {
+			rows:     newTestRowsMock([]interface{}{int64(1), nil, nil, nil}),
+			opts:     CSVOptions{Delimiter: ',', Header: true, Columns: []string{"a", "b", "c", "d"}},
+			expected: "id,name,avatar,joined_at\n1,,,\n",
+		},
+	}
+
+	for caseName, c := range cases {
+		buf := &bytes.Buffer{}
+		err := CSVWith(buf, c.rows, c.opts)
+		assert.NoError(t, err, caseName)
+		assert.Equal(t, c.expected, buf.String(), caseName)
+		c.rows.AssertCalled(t, "Close")
+	}
+}
@@ -0,0 +1,110 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/wojnosystems/vsql/vrow"
+	"github.com/wojnosystems/vsql/vrows"
+	"io"
+)
+
+// JSONLines writes every vrow to w as a JSON object on its own line, also known as newline-delimited JSON, as they are read
+//
+// Objects have the columns as keys, in the order of the columns. NULLs are null, text is a string, bytes of binary columns and columns the driver could not describe are base64 encoded strings and everything else is as encoding/json writes it
+// r is closed when JSONLines returns
+// @param w is where the JSON is written
+// @param r comes from Query() calls
+// @return err the database error encountered, or the first error writing to w
+func JSONLines(w io.Writer, r vrows.Rowser) (err error) {
+	bw := bufio.NewWriter(w)
+	err = vrow.EachValues(r, func(columns []string, values []interface{}) (stop bool, err error) {
+		if err = writeJSONObject(bw, columns, values); err != nil {
+			return true, err
+		}
+		return false, bw.WriteByte('\n')
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// JSONArray writes every vrow to w as a JSON array of objects, as they are read, so the whole result does not have to fit in memory
+//
+// Objects are written the same way as JSONLines. No vrows is written as an empty array
+// r is closed when JSONArray returns
+// @param w is where the JSON is written
+// @param r comes from Query() calls
+// @return err the database error encountered, or the first error writing to w. The array is left unterminated if there was an error, so that it is not mistaken for the complete result
+func JSONArray(w io.Writer, r vrows.Rowser) (err error) {
+	bw := bufio.NewWriter(w)
+	if err = bw.WriteByte('['); err != nil {
+		return err
+	}
+	first := true
+	err = vrow.EachValues(r, func(columns []string, values []interface{}) (stop bool, err error) {
+		if !first {
+			if err = bw.WriteByte(','); err != nil {
+				return true, err
+			}
+		}
+		first = false
+		return false, writeJSONObject(bw, columns, values)
+	})
+	if err != nil {
+		_ = bw.Flush()
+		return err
+	}
+	if _, err = bw.WriteString("]\n"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeJSONObject writes the columns and values as a JSON object, keeping the keys in the order of the columns
+func writeJSONObject(bw *bufio.Writer, columns []string, values []interface{}) (err error) {
+	if err = bw.WriteByte('{'); err != nil {
+		return err
+	}
+	for i, column := range columns {
+		if i != 0 {
+			if err = bw.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		if err = writeJSONValue(bw, column); err != nil {
+			return err
+		}
+		if err = bw.WriteByte(':'); err != nil {
+			return err
+		}
+		if err = writeJSONValue(bw, values[i]); err != nil {
+			return err
+		}
+	}
+	return bw.WriteByte('}')
+}
+
+func writeJSONValue(bw *bufio.Writer, value interface{}) (err error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = bw.Write(b)
+	return err
+}
@@ -0,0 +1,48 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestJSONLines(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := JSONLines(buf, newTestRows())
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"id":1,"name":"bob, jr.","avatar":"/w==","joined_at":"2019-07-04T12:30:00Z"}`+"\n"+
+			`{"id":2,"name":"say \"hi\"","avatar":null,"joined_at":null}`+"\n",
+		buf.String())
+}
+
+func TestJSONArray(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := JSONArray(buf, newTestRows())
+	assert.NoError(t, err)
+	var rows []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &rows))
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "say \"hi\"", rows[1]["name"])
+
+	buf.Reset()
+	err = JSONArray(buf, newTestRowsMock())
+	assert.NoError(t, err)
+	assert.Equal(t, "[]\n", buf.String())
+}
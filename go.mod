@@ -1,5 +1,11 @@
 module github.com/wojnosystems/vsql
 
-go 1.12
+go 1.20
 
 require github.com/stretchr/testify v1.3.1-0.20190311161405-34c6fa2dc709
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/stretchr/objx v0.1.0 // indirect
+)
@@ -25,6 +25,15 @@ type InterpolateStrategy interface {
 	InsertPlaceholderIntoSQL() string
 }
 
+// ReusableInterpolateStrategy is an InterpolateStrategy whose placeholders refer to parameters by number, such as Postgres' $1, $2.
+// Because the same placeholder can appear in the query more than once, a named parameter used several times only needs to be passed to the driver once: the placeholder returned by InsertPlaceholderIntoSQL the first time is written again.
+// Strategies whose placeholders are consumed in order, such as MySQL's question mark, or whose databases bind by position regardless of the number, such as Oracle's :1, must not implement this
+type ReusableInterpolateStrategy interface {
+	InterpolateStrategy
+	// ReusesPlaceholders marks the strategy as reusable. It does nothing
+	ReusesPlaceholders()
+}
+
 // InterpolationStrategyFactory builds InterpoateStrategies. Used in database drivers to tell implementing libraries how to do variable injection
 // This type is in the VSQL library for convenience
 type InterpolationStrategyFactory func() InterpolateStrategy
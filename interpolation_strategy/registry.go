@@ -0,0 +1,105 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package interpolation_strategy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Names of the dialects registered by this package
+const (
+	DialectMySQL     = "mysql"
+	DialectPostgres  = "postgres"
+	DialectSQLServer = "sqlserver"
+	DialectOracle    = "oracle"
+	DialectSQLite    = "sqlite"
+)
+
+// Other names registered for the same dialects, matching common database/sql driver names
+const (
+	// DialectMariaDB is DialectMySQL
+	DialectMariaDB = "mariadb"
+	// DialectPostgreSQL is DialectPostgres
+	DialectPostgreSQL = "postgresql"
+	// DialectMSSQL is DialectSQLServer
+	DialectMSSQL = "mssql"
+	// DialectSQLite3 is DialectSQLite
+	DialectSQLite3 = "sqlite3"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]InterpolationStrategyFactory{
+		DialectMySQL:      NewQuestionMark,
+		DialectMariaDB:    NewQuestionMark,
+		DialectPostgres:   NewDollarOrdinal,
+		DialectPostgreSQL: NewDollarOrdinal,
+		DialectSQLServer:  NewAtPOrdinal,
+		DialectMSSQL:      NewAtPOrdinal,
+		DialectOracle:     NewColonOrdinal,
+		DialectSQLite:     NewQuestionOrdinal,
+		DialectSQLite3:    NewQuestionOrdinal,
+	}
+)
+
+// Register makes a strategy factory available by dialect name, replacing any factory already registered with that name
+// Use this to add dialects, or to change the strategy used for a built-in one
+func Register(dialect string, factory InterpolationStrategyFactory) {
+	if factory == nil {
+		panic("interpolation_strategy: Register factory is nil")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[dialect] = factory
+}
+
+// Factory gets the strategy factory registered for the dialect
+// @param dialect is the name of the database, e.g. DialectPostgres
+// @return factory creates a new strategy each time it is called, or nil if an error was returned
+// @return err ErrUnknownDialect if nothing was registered with that name
+func Factory(dialect string) (factory InterpolationStrategyFactory, err error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok := registry[dialect]
+	if !ok {
+		return nil, &ErrUnknownDialect{dialect: dialect}
+	}
+	return factory, nil
+}
+
+// Dialects lists the registered dialect names, sorted
+func Dialects() (dialects []string) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	dialects = make([]string, 0, len(registry))
+	for dialect := range registry {
+		dialects = append(dialects, dialect)
+	}
+	sort.Strings(dialects)
+	return
+}
+
+// ErrUnknownDialect is returned when asking for a dialect that has not been registered
+type ErrUnknownDialect struct {
+	dialect string
+}
+
+// Error satisfies the Error interface and says which dialect was missing
+func (e ErrUnknownDialect) Error() string {
+	return fmt.Sprintf(`no interpolation strategy registered for dialect "%s"`, e.dialect)
+}
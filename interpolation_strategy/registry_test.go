@@ -0,0 +1,57 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package interpolation_strategy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFactory(t *testing.T) {
+	f, err := Factory(DialectPostgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "$1", f().InsertPlaceholderIntoSQL())
+
+	_, err = Factory("nope")
+	assert.Equal(t, &ErrUnknownDialect{dialect: "nope"}, err)
+}
+
+func TestFactory_Aliases(t *testing.T) {
+	aliases := map[string]string{
+		DialectMariaDB:    DialectMySQL,
+		DialectPostgreSQL: DialectPostgres,
+		DialectMSSQL:      DialectSQLServer,
+		DialectSQLite3:    DialectSQLite,
+	}
+	for alias, dialect := range aliases {
+		f, err := Factory(alias)
+		assert.NoError(t, err, alias)
+		assert.Equal(t, dialect, f().(Dialecter).Dialect(), alias)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("test-dialect", NewAtPOrdinal)
+	f, err := Factory("test-dialect")
+	assert.NoError(t, err)
+	assert.Equal(t, "@p1", f().InsertPlaceholderIntoSQL())
+	assert.Contains(t, Dialects(), "test-dialect")
+	assert.Contains(t, Dialects(), DialectMySQL)
+
+	assert.Panics(t, func() {
+		Register("test-dialect", nil)
+	})
+}
@@ -0,0 +1,114 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package interpolation_strategy
+
+import "strconv"
+
+// Resetter is an InterpolateStrategy that can be returned to its initial state, so that it can be used to interpolate another query
+// All of the strategies in this package are Resetters
+type Resetter interface {
+	// Reset forgets every placeholder inserted so far. The next placeholder will be the first one
+	Reset()
+}
+
+// Dialecter is an InterpolateStrategy that knows which database it is for, so that queries can be parsed the way that database does
+// All of the strategies in this package are Dialecters
+type Dialecter interface {
+	// Dialect is the name of the database, e.g. DialectPostgres
+	Dialect() string
+}
+
+// questionMark is MySQL's strategy: every placeholder is a question mark (?) and values are consumed in order
+type questionMark struct {
+}
+
+// NewQuestionMark creates the strategy used by MySQL and MariaDB: every placeholder is "?"
+// Queries are parsed as MySQL does, so a backslash escapes the next character of a string
+func NewQuestionMark() InterpolateStrategy {
+	return &questionMark{}
+}
+
+// InsertPlaceholderIntoSQL always returns "?"
+func (s *questionMark) InsertPlaceholderIntoSQL() string {
+	return "?"
+}
+
+// Reset does nothing, as question marks have no state, but is here so that all strategies can be reset
+func (s *questionMark) Reset() {
+}
+
+// Dialect is always DialectMySQL
+func (s *questionMark) Dialect() string {
+	return DialectMySQL
+}
+
+// ordinal is a strategy that numbers placeholders, starting at 1, after a prefix. e.g. $1, $2
+type ordinal struct {
+	prefix string
+	// dialect is the name of the database that uses prefix
+	dialect string
+	// count is how many placeholders have been inserted so far
+	count int
+}
+
+// reusableOrdinal is an ordinal whose database binds each number to the same parameter, however many times it appears, so placeholders can be reused
+type reusableOrdinal struct {
+	ordinal
+}
+
+// NewDollarOrdinal creates the strategy used by Postgres: $1, $2, $3...
+// Placeholders are reusable
+func NewDollarOrdinal() InterpolateStrategy {
+	return &reusableOrdinal{ordinal{prefix: "$", dialect: DialectPostgres}}
+}
+
+// NewAtPOrdinal creates the strategy used by SQL Server: @p1, @p2, @p3...
+// Placeholders are reusable
+func NewAtPOrdinal() InterpolateStrategy {
+	return &reusableOrdinal{ordinal{prefix: "@p", dialect: DialectSQLServer}}
+}
+
+// NewColonOrdinal creates the strategy used by Oracle: :1, :2, :3...
+// Placeholders are not reusable, as Oracle binds them by position, not by number
+func NewColonOrdinal() InterpolateStrategy {
+	return &ordinal{prefix: ":", dialect: DialectOracle}
+}
+
+// NewQuestionOrdinal creates the numbered strategy supported by SQLite: ?1, ?2, ?3...
+// Placeholders are reusable
+func NewQuestionOrdinal() InterpolateStrategy {
+	return &reusableOrdinal{ordinal{prefix: "?", dialect: DialectSQLite}}
+}
+
+// InsertPlaceholderIntoSQL returns the prefix followed by the next number
+func (s *ordinal) InsertPlaceholderIntoSQL() string {
+	s.count++
+	return s.prefix + strconv.Itoa(s.count)
+}
+
+// Reset starts numbering from 1 again
+func (s *ordinal) Reset() {
+	s.count = 0
+}
+
+// Dialect is the database that uses this kind of ordinal
+func (s *ordinal) Dialect() string {
+	return s.dialect
+}
+
+// ReusesPlaceholders marks the strategy as an interpolation_strategy.ReusableInterpolateStrategy
+func (s *reusableOrdinal) ReusesPlaceholders() {
+}
@@ -0,0 +1,80 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package interpolation_strategy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStrategies(t *testing.T) {
+	cases := map[string]struct {
+		factory  InterpolationStrategyFactory
+		expected []string
+		reusable bool
+		dialect  string
+	}{
+		"question mark": {
+			factory:  NewQuestionMark,
+			expected: []string{"?", "?", "?"},
+			dialect:  DialectMySQL,
+		},
+		"dollar": {
+			factory:  NewDollarOrdinal,
+			expected: []string{"$1", "$2", "$3"},
+			reusable: true,
+			dialect:  DialectPostgres,
+		},
+		"at p": {
+			factory:  NewAtPOrdinal,
+			expected: []string{"@p1", "@p2", "@p3"},
+			reusable: true,
+			dialect:  DialectSQLServer,
+		},
+		"colon": {
+			factory:  NewColonOrdinal,
+			expected: []string{":1", ":2", ":3"},
+			dialect:  DialectOracle,
+		},
+		"question number": {
+			factory:  NewQuestionOrdinal,
+			expected: []string{"?1", "?2", "?3"},
+			reusable: true,
+			dialect:  DialectSQLite,
+		},
+	}
+	for caseName, c := range cases {
+		s := c.factory()
+		for i := 0; i < 2; i++ {
+			actual := make([]string, 0, len(c.expected))
+			for range c.expected {
+				actual = append(actual, s.InsertPlaceholderIntoSQL())
+			}
+			assert.Equal(t, c.expected, actual, caseName)
+			// after Reset, the strategy must produce the same placeholders again
+			s.(Resetter).Reset()
+		}
+
+		_, ok := s.(ReusableInterpolateStrategy)
+		assert.Equal(t, c.reusable, ok, caseName)
+
+		assert.Equal(t, c.dialect, s.(Dialecter).Dialect(), caseName)
+
+		// factories must not share state
+		c.factory().InsertPlaceholderIntoSQL()
+		assert.Equal(t, c.expected[0], c.factory().InsertPlaceholderIntoSQL(), caseName)
+	}
+}
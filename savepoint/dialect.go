@@ -0,0 +1,84 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package savepoint adds nested transactions to databases that only have flat transactions, using savepoints
+package savepoint
+
+import (
+	"fmt"
+	"github.com/wojnosystems/vsql/interpolation_strategy"
+)
+
+// Dialect is the syntax a database uses for savepoints. Each is a format string, where %s is the name of the savepoint
+type Dialect struct {
+	// Savepoint creates a savepoint
+	Savepoint string
+	// Release forgets a savepoint, keeping the changes made since it was created. Empty if the database has no such statement
+	Release string
+	// RollbackTo discards the changes made since the savepoint was created
+	RollbackTo string
+}
+
+var (
+	// Standard is the SQL standard syntax, used by MySQL, MariaDB, Postgres and SQLite
+	Standard = Dialect{
+		Savepoint:  "SAVEPOINT %s",
+		Release:    "RELEASE SAVEPOINT %s",
+		RollbackTo: "ROLLBACK TO SAVEPOINT %s",
+	}
+	// SQLServer is Microsoft SQL Server's syntax, which has no release
+	SQLServer = Dialect{
+		Savepoint:  "SAVE TRANSACTION %s",
+		RollbackTo: "ROLLBACK TRANSACTION %s",
+	}
+	// Oracle is Oracle's syntax, which has no release
+	Oracle = Dialect{
+		Savepoint:  "SAVEPOINT %s",
+		RollbackTo: "ROLLBACK TO SAVEPOINT %s",
+	}
+)
+
+// dialects are the syntaxes for the dialects known to interpolation_strategy
+var dialects = map[string]Dialect{
+	interpolation_strategy.DialectMySQL:      Standard,
+	interpolation_strategy.DialectMariaDB:    Standard,
+	interpolation_strategy.DialectPostgres:   Standard,
+	interpolation_strategy.DialectPostgreSQL: Standard,
+	interpolation_strategy.DialectSQLite:     Standard,
+	interpolation_strategy.DialectSQLite3:    Standard,
+	interpolation_strategy.DialectSQLServer:  SQLServer,
+	interpolation_strategy.DialectMSSQL:      SQLServer,
+	interpolation_strategy.DialectOracle:     Oracle,
+}
+
+// DialectFor looks up the savepoint syntax by the same dialect names as interpolation_strategy.Factory
+// @return err *ErrUnknownDialect if the dialect is not known
+func DialectFor(dialect string) (d Dialect, err error) {
+	d, ok := dialects[dialect]
+	if !ok {
+		return Dialect{}, &ErrUnknownDialect{dialect: dialect}
+	}
+	return d, nil
+}
+
+// ErrUnknownDialect is returned by DialectFor when asking for a dialect it does not know
+type ErrUnknownDialect struct {
+	dialect string
+}
+
+// Error satisfies the Error interface and says which dialect was missing
+func (e ErrUnknownDialect) Error() string {
+	return fmt.Sprintf(`no savepoint syntax known for dialect "%s"`, e.dialect)
+}
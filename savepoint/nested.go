@@ -0,0 +1,151 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package savepoint
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/wojnosystems/vsql"
+	"github.com/wojnosystems/vsql/vparam"
+	"github.com/wojnosystems/vsql/vtxn"
+)
+
+// namePrefix starts the name of every savepoint, which is followed by a number unique within the transaction
+const namePrefix = "vsql_sp_"
+
+// ErrTxOptions is returned when starting a nested transaction with options. A savepoint is part of its transaction, so it can't have its own isolation level or be read-only
+var ErrTxOptions = errors.New("savepoint: nested transactions cannot have their own transaction options")
+
+// sqlNester starts transactions that can be nested
+type sqlNester struct {
+	vsql.SQLer
+	dialect Dialect
+}
+
+// NewSQLNester wraps s so that its transactions can start nested transactions using savepoints, such as for use with vsql.TxnNested
+// @param s is the database, e.g. from stdsql.New
+// @param dialect is the savepoint syntax for the database
+// @return the SQLNester. Everything other than Begin is passed through to s
+// @example
+//   nester := savepoint.NewSQLNester(stdsql.New(sqlDB, interpolation_strategy.NewDollarOrdinal), savepoint.Standard)
+//   err := vsql.TxnNested(nester, ctx, nil, func(tx vsql.QueryExecTransactioner) (commit bool, err error) {
+//     // tx is a vsql.QueryExecNestedTransactioner, so it can start a transaction nested within it
+//     err = vsql.TxnNested(tx.(vsql.TransactionNestedStarter), ctx, nil, ...)
+//     ...
+//   })
+func NewSQLNester(s vsql.SQLer, dialect Dialect) vsql.SQLNester {
+	return &sqlNester{
+		SQLer:   s,
+		dialect: dialect,
+	}
+}
+
+// Begin starts a transaction that can start nested transactions
+func (s *sqlNester) Begin(ctx context.Context, txOps vtxn.TxOptioner) (nt vsql.QueryExecNestedTransactioner, err error) {
+	tx, err := s.SQLer.Begin(ctx, txOps)
+	if err != nil {
+		return nil, err
+	}
+	return NewNested(tx, s.dialect), nil
+}
+
+// nested is a transaction, or a savepoint within one, that can start savepoints
+type nested struct {
+	vsql.QueryExecTransactioner
+	dialect Dialect
+	// lastID is shared by every savepoint in the transaction so that their names are unique
+	lastID *int
+}
+
+// NewNested wraps a transaction so that it can start nested transactions, each of which is a savepoint
+//
+// Committing a nested transaction releases its savepoint, keeping its changes for now. They are only persisted if every transaction above it is committed, too.
+// Rolling one back rolls back to its savepoint, leaving the outer transaction able to continue
+// @param tx is the outermost transaction
+// @param dialect is the savepoint syntax for the database
+func NewNested(tx vsql.QueryExecTransactioner, dialect Dialect) vsql.QueryExecNestedTransactioner {
+	return &nested{
+		QueryExecTransactioner: tx,
+		dialect:                dialect,
+		lastID:                 new(int),
+	}
+}
+
+// Begin creates a savepoint
+// @param ctx is used for the savepoint statements, including those run by Commit and Rollback
+// @param txOps must be nil, otherwise ErrTxOptions is returned
+func (n *nested) Begin(ctx context.Context, txOps vtxn.TxOptioner) (nt vsql.QueryExecNestedTransactioner, err error) {
+	if txOps != nil {
+		return nil, ErrTxOptions
+	}
+	*n.lastID++
+	sp := &savepoint{
+		nested: nested{
+			QueryExecTransactioner: n.QueryExecTransactioner,
+			dialect:                n.dialect,
+			lastID:                 n.lastID,
+		},
+		ctx:  ctx,
+		name: fmt.Sprintf("%s%d", namePrefix, *n.lastID),
+	}
+	if err = sp.exec(n.dialect.Savepoint); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+// savepoint is a nested transaction
+type savepoint struct {
+	nested
+	ctx  context.Context
+	name string
+	// done is true once the savepoint has been committed or rolled back
+	done bool
+}
+
+// Commit releases the savepoint, keeping the changes as part of the outer transaction
+// @return err sql.ErrTxDone if already committed or rolled back
+func (s *savepoint) Commit() (err error) {
+	if s.done {
+		return sql.ErrTxDone
+	}
+	s.done = true
+	return s.exec(s.dialect.Release)
+}
+
+// Rollback discards the changes made since the savepoint was created, then releases it
+// @return err sql.ErrTxDone if already committed or rolled back
+func (s *savepoint) Rollback() (err error) {
+	if s.done {
+		return sql.ErrTxDone
+	}
+	s.done = true
+	if err = s.exec(s.dialect.RollbackTo); err != nil {
+		return err
+	}
+	return s.exec(s.dialect.Release)
+}
+
+// exec runs the savepoint statement in the transaction. Does nothing if the dialect has no such statement
+func (s *savepoint) exec(format string) (err error) {
+	if format == "" {
+		return nil
+	}
+	_, err = s.QueryExecTransactioner.Exec(s.ctx, vparam.New(fmt.Sprintf(format, s.name)))
+	return err
+}
@@ -0,0 +1,92 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package savepoint
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/wojnosystems/vsql"
+	"github.com/wojnosystems/vsql/vparam"
+	"github.com/wojnosystems/vsql/vtxn"
+	"testing"
+)
+
+// onExec expects the statement to be run on the transaction
+func onExec(qet *vsql.QueryExecTransactionerMock, statement string) *mock.Call {
+	return qet.On("Exec", mock.Anything, mock.MatchedBy(func(q vparam.Queryer) bool {
+		return q.SQLQueryUnInterpolated() == statement
+	}))
+}
+
+func TestTxnNested_Savepoints(t *testing.T) {
+	ctx := context.Background()
+	qet := &vsql.QueryExecTransactionerMock{}
+	onExec(qet, "SAVEPOINT vsql_sp_1").Once().Return(nil, nil)
+	onExec(qet, "RELEASE SAVEPOINT vsql_sp_1").Once().Return(nil, nil)
+	onExec(qet, "SAVEPOINT vsql_sp_2").Once().Return(nil, nil)
+	onExec(qet, "ROLLBACK TO SAVEPOINT vsql_sp_2").Once().Return(nil, nil)
+	onExec(qet, "RELEASE SAVEPOINT vsql_sp_2").Once().Return(nil, nil)
+	qet.On("Commit").Once().Return(nil)
+	sqlerMock := &vsql.SQLerMock{}
+	sqlerMock.On("Begin", ctx, nil).Once().Return(qet, nil)
+	errInner := errors.New("inner failed")
+
+	err := vsql.TxnNested(NewSQLNester(sqlerMock, Standard), ctx, nil, func(tx vsql.QueryExecTransactioner) (commit bool, err error) {
+		err = vsql.TxnNested(tx.(vsql.TransactionNestedStarter), ctx, nil, func(inner vsql.QueryExecTransactioner) (commit bool, err error) {
+			return true, nil
+		})
+		assert.NoError(t, err)
+		err = vsql.TxnNested(tx.(vsql.TransactionNestedStarter), ctx, nil, func(inner vsql.QueryExecTransactioner) (commit bool, err error) {
+			return true, errInner
+		})
+		assert.Equal(t, errInner, err)
+		return true, nil
+	})
+
+	assert.NoError(t, err)
+	sqlerMock.AssertExpectations(t)
+	qet.AssertExpectations(t)
+}
+
+func TestNested_SQLServer(t *testing.T) {
+	ctx := context.Background()
+	qet := &vsql.QueryExecTransactionerMock{}
+	onExec(qet, "SAVE TRANSACTION vsql_sp_1").Once().Return(nil, nil)
+	onExec(qet, "ROLLBACK TRANSACTION vsql_sp_1").Once().Return(nil, nil)
+
+	sp, err := NewNested(qet, SQLServer).Begin(ctx, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, sp.Rollback())
+	assert.Equal(t, sql.ErrTxDone, sp.Rollback())
+	assert.Equal(t, sql.ErrTxDone, sp.Commit())
+	qet.AssertExpectations(t)
+}
+
+func TestNested_TxOptions(t *testing.T) {
+	_, err := NewNested(&vsql.QueryExecTransactionerMock{}, Standard).Begin(context.Background(), &vtxn.TxOption{})
+	assert.Equal(t, ErrTxOptions, err)
+}
+
+func TestDialectFor(t *testing.T) {
+	d, err := DialectFor("postgres")
+	assert.NoError(t, err)
+	assert.Equal(t, Standard, d)
+	_, err = DialectFor("nope")
+	assert.IsType(t, &ErrUnknownDialect{}, err)
+}
@@ -0,0 +1,107 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package stdsql adapts any database/sql driver to the vsql interfaces.
+// Use this when there is no dedicated vsql module for your database, such as for SQLite or SQL Server
+package stdsql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wojnosystems/vsql"
+	"github.com/wojnosystems/vsql/interpolation_strategy"
+	"github.com/wojnosystems/vsql/vtxn"
+)
+
+// db wraps a *sql.DB
+type db struct {
+	queryExecer
+	db *sql.DB
+}
+
+// New wraps an open *sql.DB so it can be used wherever a vsql.SQLer is expected
+// @param sqlDB is the database, as returned by sql.Open. Closing the SQLer closes sqlDB
+// @param factory creates the strategy that matches the driver's placeholders, e.g. interpolation_strategy.NewDollarOrdinal for Postgres
+// @return the SQLer
+// @example
+//   sqlDB, err := sql.Open("sqlite3", "file:test.db")
+//   if err != nil { return err }
+//   db := stdsql.New(sqlDB, interpolation_strategy.NewQuestionOrdinal)
+func New(sqlDB *sql.DB, factory interpolation_strategy.InterpolationStrategyFactory) vsql.SQLer {
+	return &db{
+		queryExecer: queryExecer{
+			conn:    sqlDB,
+			factory: factory,
+		},
+		db: sqlDB,
+	}
+}
+
+// NewForDialect is like New, but looks up the strategy factory by dialect name
+// @param dialect is the name the strategy is registered under, e.g. interpolation_strategy.DialectSQLite
+// @return err interpolation_strategy.ErrUnknownDialect if the dialect is not registered
+func NewForDialect(sqlDB *sql.DB, dialect string) (s vsql.SQLer, err error) {
+	factory, err := interpolation_strategy.Factory(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return New(sqlDB, factory), nil
+}
+
+// Begin starts a transaction
+// @param txOps are the options for the transaction, or nil to use the driver's defaults
+func (d *db) Begin(ctx context.Context, txOps vtxn.TxOptioner) (qet vsql.QueryExecTransactioner, err error) {
+	var opts *sql.TxOptions
+	if txOps != nil {
+		opts = txOps.ToTxOptions()
+	}
+	sqlTx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &tx{
+		queryExecer: queryExecer{
+			conn:    sqlTx,
+			factory: d.factory,
+		},
+		tx: sqlTx,
+	}, nil
+}
+
+// Ping checks that the database is reachable
+func (d *db) Ping(ctx context.Context) error {
+	return d.db.PingContext(ctx)
+}
+
+// Close closes the underlying *sql.DB
+func (d *db) Close() error {
+	return d.db.Close()
+}
+
+// tx wraps a *sql.Tx
+type tx struct {
+	queryExecer
+	tx *sql.Tx
+}
+
+// Commit persists the changes made in the transaction
+func (t *tx) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback discards the changes made in the transaction
+func (t *tx) Rollback() error {
+	return t.tx.Rollback()
+}
@@ -0,0 +1,269 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package stdsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/wojnosystems/vsql"
+	"github.com/wojnosystems/vsql/interpolation_strategy"
+	"github.com/wojnosystems/vsql/ulong"
+	"github.com/wojnosystems/vsql/vparam"
+	"github.com/wojnosystems/vsql/vrow"
+	"github.com/wojnosystems/vsql/vrows"
+	"io"
+	"testing"
+)
+
+// fakeCall is a query received by the fake driver
+type fakeCall struct {
+	query string
+	args  []driver.Value
+}
+
+// fakeDriver records the queries it is sent and answers every query with the same rows
+type fakeDriver struct {
+	calls     []fakeCall
+	committed int
+	rolled    int
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{d: d}, nil
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{d: c.d, query: query}, nil
+}
+func (c *fakeConn) Close() error {
+	return nil
+}
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{d: c.d}, nil
+}
+
+type fakeTx struct {
+	d *fakeDriver
+}
+
+func (t *fakeTx) Commit() error {
+	t.d.committed++
+	return nil
+}
+func (t *fakeTx) Rollback() error {
+	t.d.rolled++
+	return nil
+}
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.calls = append(s.d.calls, fakeCall{query: s.query, args: args})
+	return fakeResult{}, nil
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.calls = append(s.d.calls, fakeCall{query: s.query, args: args})
+	return &fakeRows{values: [][]driver.Value{{int64(1), "bob"}, {int64(2), "alice"}}}, nil
+}
+
+type fakeResult struct {
+}
+
+func (fakeResult) LastInsertId() (int64, error) {
+	return 7, nil
+}
+func (fakeResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id", "name"}
+}
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string {
+	return []string{"BIGINT", "VARCHAR"}[index]
+}
+func (r *fakeRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	return index == 1, true
+}
+func (r *fakeRows) Close() error {
+	return nil
+}
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// newFake creates a SQLer backed by a new fake driver
+func newFake(t *testing.T) (s vsql.SQLer, d *fakeDriver) {
+	d = &fakeDriver{}
+	sql.Register(t.Name(), d)
+	sqlDB, err := sql.Open(t.Name(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(sqlDB, interpolation_strategy.NewDollarOrdinal), d
+}
+
+func TestDB_Query(t *testing.T) {
+	ctx := context.Background()
+	s, d := newFake(t)
+	defer func() { _ = s.Close() }()
+
+	names := make([]string, 0, 2)
+	err := vrow.QueryEach(s, ctx, vparam.NewNamedWithData("select id, name from users where tenant = :tenant and id in (:ids)",
+		map[string]interface{}{"tenant": 3, "ids": []int{1, 2}}),
+		func(ro vrows.Rower) (stop bool, err error) {
+			assert.Equal(t, []string{"id", "name"}, ro.Columns())
+			var id int
+			var name string
+			err = ro.Scan(&id, &name)
+			names = append(names, name)
+			return false, err
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bob", "alice"}, names)
+	assert.Equal(t, []fakeCall{{
+		query: "select id, name from users where tenant = $1 and id in ($2, $3)",
+		args:  []driver.Value{int64(3), int64(1), int64(2)},
+	}}, d.calls)
+}
+
+func TestDB_QueryColumnTypes(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newFake(t)
+	defer func() { _ = s.Close() }()
+
+	ok, err := vrow.QueryOne(s, ctx, vparam.New("select id, name from users"), func(ro vrows.Rower) (err error) {
+		columnTypes, err := ro.ColumnTypes()
+		assert.NoError(t, err)
+		assert.Len(t, columnTypes, 2)
+		assert.Equal(t, "name", columnTypes[1].Name())
+		assert.Equal(t, "VARCHAR", columnTypes[1].DatabaseTypeName())
+		nullable, ok := columnTypes[1].Nullable()
+		assert.True(t, nullable)
+		assert.True(t, ok)
+		_, ok = columnTypes[1].Length()
+		assert.False(t, ok, "the fake driver does not report lengths")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestDB_InsertExec(t *testing.T) {
+	ctx := context.Background()
+	s, d := newFake(t)
+	defer func() { _ = s.Close() }()
+
+	ir, err := s.Insert(ctx, vparam.NewAppendWithData("insert into users (name) values (?)", "bob"))
+	assert.NoError(t, err)
+	id, err := ir.LastInsertId()
+	assert.NoError(t, err)
+	assert.Equal(t, ulong.New(7), id)
+
+	r, err := s.Exec(ctx, vparam.New("delete from users"))
+	assert.NoError(t, err)
+	affected, err := r.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, ulong.New(1), affected)
+	assert.Len(t, d.calls, 2)
+
+	_, err = s.Exec(ctx, vparam.NewNamed("delete from users where id = :id"))
+	assert.Error(t, err, "interpolation errors are returned before reaching the driver")
+	assert.Len(t, d.calls, 2)
+}
+
+func TestDB_Txn(t *testing.T) {
+	ctx := context.Background()
+	s, d := newFake(t)
+	defer func() { _ = s.Close() }()
+
+	err := vsql.Txn(s, ctx, nil, func(qe vsql.QueryExecer) (commit bool, err error) {
+		_, err = qe.Exec(ctx, vparam.NewAppendWithData("update users set name = ?", "bob"))
+		return true, err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, d.committed)
+	assert.Equal(t, "update users set name = $1", d.calls[0].query)
+}
+
+func TestDB_Prepare(t *testing.T) {
+	ctx := context.Background()
+	s, d := newFake(t)
+	defer func() { _ = s.Close() }()
+
+	stmt, err := s.Prepare(ctx, vparam.NewNamed("update users set name = :name where id = :id"))
+	assert.NoError(t, err)
+	defer func() { _ = stmt.Close() }()
+
+	_, err = stmt.Exec(ctx, vparam.NewNamedData(map[string]interface{}{"id": 5, "name": "bob"}))
+	assert.NoError(t, err)
+	assert.Equal(t, []fakeCall{{
+		query: "update users set name = $1 where id = $2",
+		args:  []driver.Value{"bob", int64(5)},
+	}}, d.calls)
+}
+
+func TestDB_PrepareSlice(t *testing.T) {
+	ctx := context.Background()
+	s, d := newFake(t)
+	defer func() { _ = s.Close() }()
+
+	stmt, err := s.Prepare(ctx, vparam.NewNamedWithData("select * from users where id in (:ids)", map[string]interface{}{"ids": []int{1, 2}}))
+	assert.NoError(t, err)
+	defer func() { _ = stmt.Close() }()
+
+	_, err = stmt.Query(ctx, vparam.NewNamedData(map[string]interface{}{"ids": []int{3, 4}}))
+	assert.NoError(t, err)
+
+	_, err = stmt.Query(ctx, vparam.NewNamedData(map[string]interface{}{"ids": []int{5, 6, 7}}))
+	assert.Equal(t, &ErrStatementMismatch{
+		prepared:     "select * from users where id in ($1, $2)",
+		interpolated: "select * from users where id in ($1, $2, $3)",
+	}, err)
+	assert.Equal(t, []fakeCall{{
+		query: "select * from users where id in ($1, $2)",
+		args:  []driver.Value{int64(3), int64(4)},
+	}}, d.calls)
+}
+
+func TestNewForDialect(t *testing.T) {
+	_, err := NewForDialect(nil, "nope")
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package stdsql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wojnosystems/vsql/interpolation_strategy"
+	"github.com/wojnosystems/vsql/vparam"
+	"github.com/wojnosystems/vsql/vresult"
+	"github.com/wojnosystems/vsql/vrows"
+	"github.com/wojnosystems/vsql/vstmt"
+)
+
+// conn is what *sql.DB, *sql.Tx and *sql.Conn have in common
+type conn interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// queryExecer implements vsql.QueryExecer for both databases and transactions
+type queryExecer struct {
+	conn    conn
+	factory interpolation_strategy.InterpolationStrategyFactory
+}
+
+// interpolate converts the vparam query into the SQL and arguments for database/sql
+func (q *queryExecer) interpolate(query vparam.Queryer) (sqlQuery string, params []interface{}, err error) {
+	return query.Interpolate(query.SQLQueryUnInterpolated(), q.factory())
+}
+
+// Query runs a query that returns rows
+func (q *queryExecer) Query(ctx context.Context, query vparam.Queryer) (rows vrows.Rowser, err error) {
+	sqlQuery, params, err := q.interpolate(query)
+	if err != nil {
+		return nil, err
+	}
+	sqlRows, err := q.conn.QueryContext(ctx, sqlQuery, params...)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(sqlRows), nil
+}
+
+// Insert runs a query that creates rows
+func (q *queryExecer) Insert(ctx context.Context, query vparam.Queryer) (res vresult.InsertResulter, err error) {
+	sqlQuery, params, err := q.interpolate(query)
+	if err != nil {
+		return nil, err
+	}
+	r, err := q.conn.ExecContext(ctx, sqlQuery, params...)
+	if err != nil {
+		return nil, err
+	}
+	return &insertResult{result{r: r}}, nil
+}
+
+// Exec runs a query that does not return rows
+func (q *queryExecer) Exec(ctx context.Context, query vparam.Queryer) (res vresult.Resulter, err error) {
+	sqlQuery, params, err := q.interpolate(query)
+	if err != nil {
+		return nil, err
+	}
+	r, err := q.conn.ExecContext(ctx, sqlQuery, params...)
+	if err != nil {
+		return nil, err
+	}
+	return &result{r: r}, nil
+}
+
+// Prepare compiles the query. The values of query are not used, only those given when the statement is run
+// Slice values already set on query decide how many placeholders they are expanded into. Values given when the statement is run must expand into the same number, or *ErrStatementMismatch is returned
+func (q *queryExecer) Prepare(ctx context.Context, query vparam.Queryer) (stmt vstmt.Statementer, err error) {
+	prepared := query.SQLQueryInterpolated(q.factory())
+	s, err := q.conn.PrepareContext(ctx, prepared)
+	if err != nil {
+		return nil, err
+	}
+	return &statement{
+		stmt:     s,
+		query:    query.SQLQueryUnInterpolated(),
+		prepared: prepared,
+		factory:  q.factory,
+	}, nil
+}
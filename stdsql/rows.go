@@ -0,0 +1,106 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package stdsql
+
+import (
+	"database/sql"
+	"github.com/wojnosystems/vsql/ulong"
+	"github.com/wojnosystems/vsql/vrows"
+)
+
+// rows wraps *sql.Rows. It is also the Rower for the current row, as database/sql only has one row at a time
+type rows struct {
+	rows    *sql.Rows
+	columns []string
+}
+
+func newRows(r *sql.Rows) *rows {
+	return &rows{rows: r}
+}
+
+// Next advances to the next row
+// @return row the next row, or nil if there are no more rows
+func (r *rows) Next() (row vrows.Rower) {
+	if !r.rows.Next() {
+		return nil
+	}
+	return r
+}
+
+// Err is the error that ended iteration, if any
+func (r *rows) Err() error {
+	return r.rows.Err()
+}
+
+// NextResultSet moves on to the next result set, if the driver supports more than one
+func (r *rows) NextResultSet() (ok bool) {
+	// the new result set has its own columns
+	r.columns = nil
+	return r.rows.NextResultSet()
+}
+
+// Close releases the rows
+func (r *rows) Close() error {
+	return r.rows.Close()
+}
+
+// Columns returns the names of the columns, or nil if the rows have been closed
+func (r *rows) Columns() (columnNames []string) {
+	if r.columns == nil {
+		r.columns, _ = r.rows.Columns()
+	}
+	return r.columns
+}
+
+// ColumnTypes describes the columns, as far as the driver supports it. The *sql.ColumnType are returned as-is
+func (r *rows) ColumnTypes() (columnTypes []vrows.ColumnTyper, err error) {
+	types, err := r.rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	columnTypes = make([]vrows.ColumnTyper, len(types))
+	for i, t := range types {
+		columnTypes[i] = t
+	}
+	return
+}
+
+// Scan copies the columns of the current row into the destinations
+func (r *rows) Scan(destination ...interface{}) (err error) {
+	return r.rows.Scan(destination...)
+}
+
+// result wraps sql.Result
+type result struct {
+	r sql.Result
+}
+
+// RowsAffected is the number of rows changed by the query
+func (r *result) RowsAffected() (rowsAffected ulong.ULong, err error) {
+	n, err := r.r.RowsAffected()
+	return ulong.NewInt64(n), err
+}
+
+// insertResult wraps sql.Result for inserts
+type insertResult struct {
+	result
+}
+
+// LastInsertId is the id generated by the insert, if the database supports it
+func (r *insertResult) LastInsertId() (id ulong.ULong, err error) {
+	n, err := r.r.LastInsertId()
+	return ulong.NewInt64(n), err
+}
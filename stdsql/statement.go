@@ -0,0 +1,115 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package stdsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/wojnosystems/vsql/interpolation_strategy"
+	"github.com/wojnosystems/vsql/vparam"
+	"github.com/wojnosystems/vsql/vresult"
+	"github.com/wojnosystems/vsql/vrows"
+)
+
+// statement wraps a *sql.Stmt
+type statement struct {
+	stmt *sql.Stmt
+	// query is the un-interpolated query the statement was prepared with. The parameters are interpolated against it to put the values in order
+	query string
+	// prepared is the interpolated query sent to the database
+	prepared string
+	factory  interpolation_strategy.InterpolationStrategyFactory
+}
+
+// params orders the values to match the placeholders of the prepared query
+// @return err *ErrStatementMismatch if the values need different placeholders than were prepared, such as a slice with a different length
+func (s *statement) params(query vparam.Parameterer) (params []interface{}, err error) {
+	interpolated, params, err := query.Interpolate(s.query, s.factory())
+	if err != nil {
+		return nil, err
+	}
+	if interpolated != s.prepared {
+		return nil, &ErrStatementMismatch{prepared: s.prepared, interpolated: interpolated}
+	}
+	return
+}
+
+// Query runs the statement with the parameters, returning rows
+func (s *statement) Query(ctx context.Context, query vparam.Parameterer) (rows vrows.Rowser, err error) {
+	params, err := s.params(query)
+	if err != nil {
+		return nil, err
+	}
+	sqlRows, err := s.stmt.QueryContext(ctx, params...)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(sqlRows), nil
+}
+
+// Insert runs the statement with the parameters, creating rows
+func (s *statement) Insert(ctx context.Context, query vparam.Parameterer) (res vresult.InsertResulter, err error) {
+	params, err := s.params(query)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.stmt.ExecContext(ctx, params...)
+	if err != nil {
+		return nil, err
+	}
+	return &insertResult{result{r: r}}, nil
+}
+
+// Exec runs the statement with the parameters
+func (s *statement) Exec(ctx context.Context, query vparam.Parameterer) (res vresult.Resulter, err error) {
+	params, err := s.params(query)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.stmt.ExecContext(ctx, params...)
+	if err != nil {
+		return nil, err
+	}
+	return &result{r: r}, nil
+}
+
+// Close releases the prepared statement
+func (s *statement) Close() error {
+	return s.stmt.Close()
+}
+
+// ErrStatementMismatch is returned when the values given to a prepared statement need different placeholders than those it was prepared with.
+// This happens when a slice is expanded into a different number of placeholders. Prepare the statement with a slice of the same length, or do not prepare it
+type ErrStatementMismatch struct {
+	prepared     string
+	interpolated string
+}
+
+// Prepared is the query the statement was prepared with
+func (e ErrStatementMismatch) Prepared() string {
+	return e.prepared
+}
+
+// Interpolated is the query the values would need
+func (e ErrStatementMismatch) Interpolated() string {
+	return e.interpolated
+}
+
+// Error satisfies the Error interface and shows both queries
+func (e ErrStatementMismatch) Error() string {
+	return fmt.Sprintf(`the values do not match the placeholders of the prepared statement, were any slices a different length? prepared "%s" but the values need "%s"`, e.prepared, e.interpolated)
+}
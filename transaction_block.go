@@ -17,81 +17,177 @@ package vsql
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/wojnosystems/vsql/vtxn"
 	"runtime/debug"
 )
 
 // Txn creates a transaction in a block, vastly cleaning up transaction boiler-plate
+// The transaction handed to block is a TransactionHooker, see OnAfterCommit
 // @vparam s is the database connection (SQLer interface implementation) to use to start the transaction
 // @vparam ctx is the context to use when starting the transaction
 // @vparam txOps are the options to use when starting the transaction, or nil to use the default
 // @vparam block is the func closure to use within the transaction. When this method ends, the transaction will either be rolled back or committed. If you pass true for rollback or return non-nil for error, the transaction will be rolled back. If rollback is false (the default) and the err is nil (the default), then the transactions will be committed
-// @return err the error encountered during Begin, your block call, Rollback, or Commit
+// @return err the error encountered during Begin, your block call, Rollback, or Commit. A *PanicError if block panicked. Errors from Rollback are joined with the error that caused it as an *ErrRollbackFailed
 func Txn(s SQLer, ctx context.Context, txOps vtxn.TxOptioner, block func(t QueryExecer) (commit bool, err error)) (err error) {
+	return txn(s, ctx, txOps, false, block)
+}
+
+// TxnRepanic is Txn, but if block panics, it panics again with the original value once the transaction has been rolled back, instead of returning a *PanicError
+// Use this if you prefer panics to crash or to be handled by your own recovery code
+func TxnRepanic(s SQLer, ctx context.Context, txOps vtxn.TxOptioner, block func(t QueryExecer) (commit bool, err error)) (err error) {
+	return txn(s, ctx, txOps, true, block)
+}
+
+// txn is Txn, re-panicking after rolling back if repanic is true
+func txn(s SQLer, ctx context.Context, txOps vtxn.TxOptioner, repanic bool, block func(t QueryExecer) (commit bool, err error)) (err error) {
 	var tx QueryExecTransactioner
 	tx, err = s.Begin(ctx, txOps)
 	if err != nil {
 		return
 	}
-	func() {
-		// didAttemptRollback guards against an infinite loop recursion with rollback triggering crashes that are un-caught
-		didAttemptRollback := false
-		defer func() {
-			// This defer ensures that we rollback transactions, even when panics occur
-			if r := recover(); r != nil {
-				if !didAttemptRollback {
-					_ = tx.Rollback()
-				}
-				// regurgitate the panic for debugging
-				err = fmt.Errorf(`panic: %v\n%s`, r, debug.Stack())
-			}
-		}()
-		commit := true
-		commit, err = block(tx)
-		if !commit || err != nil {
-			didAttemptRollback = true
-			_ = tx.Rollback()
-		} else {
-			err = tx.Commit()
-		}
-	}()
-	return
+	if joined, ok := tx.(*joinedTx); ok {
+		// the transaction from NewContextSQLer belongs to an enclosing TxnContext block, which ends it and runs the hooks registered on it
+		return joinedResult(block(joined.QueryExecTransactioner))
+	}
+	hooked := &hookedTx{QueryExecTransactioner: tx, txHooks: &txHooks{}}
+	return endTxn(tx, hooked.txHooks, nil, repanic, func() (commit bool, err error) {
+		return block(hooked)
+	})
 }
 
 // TxnNested creates a transaction in a block but also allows for nested transactions, assuming the implementation supports that
-// @vparam s is the database connection (SQLNester interface implementation) to use to start the transaction
+// The transaction handed to block is a TransactionHooker. Hooks registered on transactions nested within it are run when it ends
+// @vparam s is the database connection (SQLNester interface implementation) to use to start the transaction, or a QueryExecNestedTransactioner to start a transaction nested within it
 // @vparam ctx is the context to use when starting the transaction
 // @vparam txOps are the options to use when starting the transaction, or nil to use the default
 // @vparam block is the func closure to use within the transaction. When this method ends, the transaction will either be rolled back or committed. If you pass true for rollback or return non-nil for error, the transaction will be rolled back. If rollback is false (the default) and the err is nil (the default), then the transactions will be committed
-// @return err the error encountered during Begin, your block call, Rollback, or Commit
-func TxnNested(s SQLNester, ctx context.Context, txOps vtxn.TxOptioner, block func(t QueryExecTransactioner) (rollback bool, err error)) (err error) {
+// @return err the error encountered during Begin, your block call, Rollback, or Commit. See Txn
+func TxnNested(s TransactionNestedStarter, ctx context.Context, txOps vtxn.TxOptioner, block func(t QueryExecTransactioner) (rollback bool, err error)) (err error) {
+	return txnNested(s, ctx, txOps, false, block)
+}
+
+// TxnNestedRepanic is TxnNested, but if block panics, it panics again with the original value once the transaction has been rolled back. See TxnRepanic
+func TxnNestedRepanic(s TransactionNestedStarter, ctx context.Context, txOps vtxn.TxOptioner, block func(t QueryExecTransactioner) (rollback bool, err error)) (err error) {
+	return txnNested(s, ctx, txOps, true, block)
+}
+
+// txnNested is TxnNested, re-panicking after rolling back if repanic is true
+func txnNested(s TransactionNestedStarter, ctx context.Context, txOps vtxn.TxOptioner, repanic bool, block func(t QueryExecTransactioner) (rollback bool, err error)) (err error) {
 	var tx QueryExecNestedTransactioner
 	tx, err = s.Begin(ctx, txOps)
 	if err != nil {
 		return
 	}
+	hooked := &hookedNestedTx{QueryExecNestedTransactioner: tx, txHooks: &txHooks{}}
+	var parent *txHooks
+	if p, ok := s.(*hookedNestedTx); ok {
+		// s is the transaction handed to an enclosing TxnNested block
+		parent = p.txHooks
+	}
+	return endTxn(tx, hooked.txHooks, parent, repanic, func() (commit bool, err error) {
+		return block(hooked)
+	})
+}
+
+// endTxn runs the block, then commits or rolls back the transaction, running the hooks as needed
+// @param parent is the hooks of the transaction tx is nested in, if any. Nested transactions hand their hooks to parent instead of running them
+// @param repanic panics again with the value the block panicked with, once the transaction has been rolled back, instead of returning a *PanicError
+// @return err from the block, the before commit hooks, or Commit, a *PanicError if the block panicked, joined with an *ErrRollbackFailed if rolling back failed
+func endTxn(tx Transactioner, hooks *txHooks, parent *txHooks, repanic bool, block func() (commit bool, err error)) (err error) {
+	var panicErr *PanicError
+	// committed is set once Commit succeeds. From then on, nothing may roll back
+	committed := false
 	func() {
 		// didAttemptRollback guards against an infinite loop recursion with rollback triggering crashes that are un-caught
 		didAttemptRollback := false
 		defer func() {
 			// This defer ensures that we rollback transactions, even when panics occur
 			if r := recover(); r != nil {
+				// keep the panic for debugging
+				panicErr = &PanicError{value: r, stack: debug.Stack()}
+				err = panicErr
 				if !didAttemptRollback {
-					_ = tx.Rollback()
+					didAttemptRollback = true
+					err = joinRollbackErr(err, tx.Rollback())
+					hooks.rolledBack()
 				}
-				// regurgitate the panic for debugging
-				err = fmt.Errorf(`panic: %v\n%s`, r, debug.Stack())
 			}
 		}()
 		commit := true
-		commit, err = block(tx)
+		commit, err = block()
+		if commit && err == nil && parent == nil {
+			err = hooks.runBeforeCommit()
+		}
 		if !commit || err != nil {
 			didAttemptRollback = true
-			_ = tx.Rollback()
+			err = joinRollbackErr(err, tx.Rollback())
+			hooks.rolledBack()
+		} else if err = tx.Commit(); err != nil {
+			hooks.rolledBack()
 		} else {
-			err = tx.Commit()
+			committed = true
 		}
 	}()
+	if committed {
+		// outside of the recover above, as the transaction can no longer be rolled back. A panicking hook is the caller's to handle
+		hooks.committed(parent)
+	}
+	if panicErr != nil && repanic {
+		panic(panicErr.value)
+	}
 	return
 }
+
+// joinRollbackErr adds the error from Rollback, if any, to the error that caused the rollback, so that neither is lost
+func joinRollbackErr(err error, rollbackErr error) error {
+	if rollbackErr == nil {
+		return err
+	}
+	return errors.Join(err, &ErrRollbackFailed{err: rollbackErr})
+}
+
+// PanicError is returned by Txn and TxnNested when their block panicked. The transaction was rolled back
+type PanicError struct {
+	value interface{}
+	stack []byte
+}
+
+// Value is what the block panicked with
+func (e PanicError) Value() interface{} {
+	return e.value
+}
+
+// Stack is the stack trace of the goroutine at the time of the panic
+func (e PanicError) Stack() []byte {
+	return e.stack
+}
+
+// Error satisfies the Error interface and includes the panic value and stack
+func (e PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", e.value, e.stack)
+}
+
+// Unwrap is the panic value if the block panicked with an error, so errors.Is and errors.As can find it
+func (e PanicError) Unwrap() error {
+	if err, ok := e.value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// ErrRollbackFailed is joined with the error returned by Txn and TxnNested when rolling back the transaction also failed
+type ErrRollbackFailed struct {
+	err error
+}
+
+// Error satisfies the Error interface and includes the error from Rollback
+func (e ErrRollbackFailed) Error() string {
+	return fmt.Sprintf("rollback failed: %s", e.err)
+}
+
+// Unwrap is the error from Rollback
+func (e ErrRollbackFailed) Unwrap() error {
+	return e.err
+}
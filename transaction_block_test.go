@@ -112,7 +112,6 @@ func TestTxn_PanicRollback(t *testing.T) {
 
 	err := Txn(sqlerMock, ctx, nil, func(t QueryExecer) (commit bool, err error) {
 		panic("boom")
-		return true, nil
 	})
 
 	if err == nil {
@@ -214,7 +213,6 @@ func TestTxnNested_PanicRollback(t *testing.T) {
 
 	err := TxnNested(sqlerMock, ctx, nil, func(t QueryExecTransactioner) (commit bool, err error) {
 		panic("boom")
-		return true, nil
 	})
 
 	if err == nil {
@@ -224,3 +222,128 @@ func TestTxnNested_PanicRollback(t *testing.T) {
 	sqlerMock.AssertExpectations(t)
 	qet.AssertExpectations(t)
 }
+
+func TestTxn_PanicError(t *testing.T) {
+	errPanic := errors.New("boom")
+	errRollback := errors.New("connection lost")
+	ctx := context.Background()
+
+	qet := &QueryExecTransactionerMock{}
+	qet.On("Rollback").
+		Once().
+		Return(errRollback)
+
+	sqlerMock := &SQLerMock{}
+	sqlerMock.On("Begin", ctx, nil).
+		Once().
+		Return(qet, nil)
+
+	err := Txn(sqlerMock, ctx, nil, func(t QueryExecer) (commit bool, err error) {
+		panic(errPanic)
+	})
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatal("expected a *PanicError but got", err)
+	}
+	if panicErr.Value() != errPanic || len(panicErr.Stack()) == 0 {
+		t.Error("expected the panic value and stack to be kept")
+	}
+	if !errors.Is(err, errPanic) {
+		t.Error("expected the panic value to be found with errors.Is")
+	}
+	var rollbackErr *ErrRollbackFailed
+	if !errors.As(err, &rollbackErr) || !errors.Is(err, errRollback) {
+		t.Error("expected the rollback error to be joined with the panic")
+	}
+
+	sqlerMock.AssertExpectations(t)
+	qet.AssertExpectations(t)
+}
+
+func TestTxn_ErrRollbackFailed(t *testing.T) {
+	forceErr := errors.New("boom")
+	errRollback := errors.New("connection lost")
+	ctx := context.Background()
+
+	qet := &QueryExecTransactionerMock{}
+	qet.On("Rollback").
+		Once().
+		Return(errRollback)
+
+	sqlerMock := &SQLerMock{}
+	sqlerMock.On("Begin", ctx, nil).
+		Once().
+		Return(qet, nil)
+
+	err := Txn(sqlerMock, ctx, nil, func(t QueryExecer) (commit bool, err error) {
+		return true, forceErr
+	})
+
+	if !errors.Is(err, forceErr) || !errors.Is(err, errRollback) {
+		t.Error("expected both the block and rollback errors but got", err)
+	}
+
+	sqlerMock.AssertExpectations(t)
+	qet.AssertExpectations(t)
+}
+
+func TestTxnRepanic(t *testing.T) {
+	ctx := context.Background()
+
+	qet := &QueryExecTransactionerMock{}
+	qet.On("Rollback").
+		Once().
+		Return(nil)
+
+	sqlerMock := &SQLerMock{}
+	sqlerMock.On("Begin", ctx, nil).
+		Once().
+		Return(qet, nil)
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Error("expected the original panic value but got", r)
+		}
+		qet.AssertExpectations(t)
+	}()
+	_ = TxnRepanic(sqlerMock, ctx, nil, func(t QueryExecer) (commit bool, err error) {
+		panic("boom")
+	})
+	t.Error("expected TxnRepanic to panic")
+}
+
+func TestTxnNestedRepanic(t *testing.T) {
+	ctx := context.Background()
+
+	qet := &QueryExecNestedTransactionerMock{}
+	qet.On("Rollback").
+		Times(2).
+		Return(nil)
+
+	sqlerMock := &SQLNesterMock{}
+	sqlerMock.On("Begin", ctx, nil).
+		Times(2).
+		Return(qet, nil)
+
+	// the choice is per call, so TxnNested still returns the panic
+	err := TxnNested(sqlerMock, ctx, nil, func(t QueryExecTransactioner) (commit bool, err error) {
+		panic("boom")
+	})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Error("expected a *PanicError but got", err)
+	}
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Error("expected the original panic value but got", r)
+		}
+		sqlerMock.AssertExpectations(t)
+		qet.AssertExpectations(t)
+	}()
+	_ = TxnNestedRepanic(sqlerMock, ctx, nil, func(t QueryExecTransactioner) (commit bool, err error) {
+		panic("boom")
+	})
+	t.Error("expected TxnNestedRepanic to panic")
+}
@@ -0,0 +1,212 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vsql
+
+import (
+	"context"
+	"errors"
+	"github.com/wojnosystems/vsql/vparam"
+	"github.com/wojnosystems/vsql/vresult"
+	"github.com/wojnosystems/vsql/vrows"
+	"github.com/wojnosystems/vsql/vstmt"
+	"github.com/wojnosystems/vsql/vtxn"
+	"reflect"
+)
+
+// txContextKey is the context.Context key for the contextTx stored by ContextWithTx and TxnContext
+type txContextKey struct{}
+
+// contextTx is the transaction carried by a context
+type contextTx struct {
+	tx QueryExecTransactioner
+	// owner is what started tx, or nil if that is not known
+	owner interface{}
+}
+
+// ErrJoinedTxnRollback is returned by TxnContext when a block that joined an existing transaction asked for it to be rolled back without returning an error.
+// Only the block that started the transaction can end it, so this is returned instead, to let it decide
+var ErrJoinedTxnRollback = errors.New("vsql: a block that joined an existing transaction did not commit")
+
+// ErrJoinedTxnOtherSQLer is returned by TxnContext when the context carries a transaction that was started by a different SQLer, such as one for another database
+var ErrJoinedTxnOtherSQLer = errors.New("vsql: the context carries a transaction started by a different SQLer")
+
+// ContextWithTx stores the transaction in the context, so that code it is passed to can run in the transaction without being handed it
+// It is not known which SQLer started tx, so TxnContext, NewContextQueryExecer and NewContextSQLer use it whatever SQLer they were given
+// @return a child of ctx carrying tx
+func ContextWithTx(ctx context.Context, tx QueryExecTransactioner) context.Context {
+	return context.WithValue(ctx, txContextKey{}, contextTx{tx: tx})
+}
+
+// TxFromContext gets the transaction stored by ContextWithTx or TxnContext
+// @return ok false if ctx has no transaction
+func TxFromContext(ctx context.Context) (tx QueryExecTransactioner, ok bool) {
+	c, ok := ctx.Value(txContextKey{}).(contextTx)
+	return c.tx, ok
+}
+
+// txFromContextFor gets the transaction carried by ctx, unless it is known to have been started by something other than owner
+// @return ok false if ctx has no transaction
+// @return sameOwner false if the transaction was started by something other than owner
+func txFromContextFor(ctx context.Context, owner interface{}) (tx QueryExecTransactioner, ok bool, sameOwner bool) {
+	c, ok := ctx.Value(txContextKey{}).(contextTx)
+	if !ok {
+		return nil, false, false
+	}
+	return c.tx, true, c.owner == nil || isSameOwner(c.owner, unwrapContextOwner(owner))
+}
+
+// unwrapContextOwner gets what NewContextSQLer and NewContextQueryExecer were created with, so that they are the same owner as the SQLer they wrap
+func unwrapContextOwner(owner interface{}) interface{} {
+	for {
+		switch c := owner.(type) {
+		case *contextSQLer:
+			owner = c.base
+		case *contextQueryExecer:
+			owner = c.base
+		default:
+			return owner
+		}
+	}
+}
+
+// isSameOwner compares owners without panicking on types that cannot be compared, which are never the same
+func isSameOwner(a, b interface{}) bool {
+	ta := reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) || !ta.Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// joinedResult is what a block that joined an existing transaction returns, as it cannot end the transaction
+func joinedResult(commit bool, err error) error {
+	if err == nil && !commit {
+		return ErrJoinedTxnRollback
+	}
+	return err
+}
+
+// TxnContext is Txn, but the transaction is carried by the context handed to block, rather than passed to it. Use it with NewContextQueryExecer
+//
+// If ctx already carries a transaction, block joins it: no new transaction is started and block's commit flag and errors are left for the block that started the transaction to act on.
+// txOps are ignored when joining. Use NewContextSQLer to have Txn join the transaction too
+// @return err the error encountered during Begin, your block call, Rollback or Commit. ErrJoinedTxnRollback if block joined a transaction and did not commit, but returned no error. ErrJoinedTxnOtherSQLer if the transaction in ctx was started by a TxnContext for a different SQLer
+// @example
+//   db := vsql.NewContextQueryExecer(sqler)
+//   // users.Create and audit.Log call db.Exec(ctx, ...) and know nothing of the transaction
+//   err := vsql.TxnContext(sqler, ctx, nil, func(ctx context.Context) (commit bool, err error) {
+//     if err = users.Create(ctx, db, u); err != nil { return }
+//     return true, audit.Log(ctx, db, "created user")
+//   })
+func TxnContext(s SQLer, ctx context.Context, txOps vtxn.TxOptioner, block func(ctx context.Context) (commit bool, err error)) (err error) {
+	if _, ok, sameOwner := txFromContextFor(ctx, s); ok {
+		if !sameOwner {
+			return ErrJoinedTxnOtherSQLer
+		}
+		return joinedResult(block(ctx))
+	}
+	return Txn(s, ctx, txOps, func(tx QueryExecer) (commit bool, err error) {
+		owned := contextTx{tx: tx.(QueryExecTransactioner), owner: unwrapContextOwner(s)}
+		return block(context.WithValue(ctx, txContextKey{}, owned))
+	})
+}
+
+// contextQueryExecer runs queries in the context's transaction, if any
+type contextQueryExecer struct {
+	base QueryExecer
+}
+
+// NewContextQueryExecer creates a QueryExecer that runs each call in the transaction carried by its context, see ContextWithTx and TxnContext, and on base otherwise
+// This lets code that takes a QueryExecer take part in transactions started far above it
+// @param base is used when the context has no transaction, or one that a TxnContext started for a different SQLer, usually the SQLer
+func NewContextQueryExecer(base QueryExecer) QueryExecer {
+	return &contextQueryExecer{base: base}
+}
+
+// target is the transaction in ctx, or base
+func (c *contextQueryExecer) target(ctx context.Context) QueryExecer {
+	if tx, ok, sameOwner := txFromContextFor(ctx, c.base); ok && sameOwner {
+		return tx
+	}
+	return c.base
+}
+
+// Query runs the query in the context's transaction, or on base
+func (c *contextQueryExecer) Query(ctx context.Context, query vparam.Queryer) (rows vrows.Rowser, err error) {
+	return c.target(ctx).Query(ctx, query)
+}
+
+// Insert runs the insert in the context's transaction, or on base
+func (c *contextQueryExecer) Insert(ctx context.Context, query vparam.Queryer) (result vresult.InsertResulter, err error) {
+	return c.target(ctx).Insert(ctx, query)
+}
+
+// Exec runs the statement in the context's transaction, or on base
+func (c *contextQueryExecer) Exec(ctx context.Context, query vparam.Queryer) (result vresult.Resulter, err error) {
+	return c.target(ctx).Exec(ctx, query)
+}
+
+// Prepare prepares the statement in the context's transaction, or on base
+func (c *contextQueryExecer) Prepare(ctx context.Context, query vparam.Queryer) (stmt vstmt.Statementer, err error) {
+	return c.target(ctx).Prepare(ctx, query)
+}
+
+// contextSQLer is an SQLer whose transactions join the one carried by the context, if any
+type contextSQLer struct {
+	*contextQueryExecer
+	base SQLer
+}
+
+// NewContextSQLer creates an SQLer that runs each call in the transaction carried by its context, like NewContextQueryExecer, and whose Begin returns that transaction instead of starting another
+// Use this to pass to code that calls Txn, so that the Txn joins a transaction started by TxnContext, rather than starting a second, independent, one
+// The transaction returned by Begin when joining cannot be ended: Commit does nothing and Rollback returns ErrJoinedTxnRollback, leaving the block that started it to decide. Txn blocks that join return what TxnContext blocks that join do, their panics are recovered by the block that started the transaction, and their hooks are registered on the joined transaction
+// @param base is used when the context has no transaction, or one that a TxnContext started for a different SQLer
+func NewContextSQLer(base SQLer) SQLer {
+	return &contextSQLer{contextQueryExecer: &contextQueryExecer{base: base}, base: base}
+}
+
+// Begin returns the transaction in ctx, or starts one on base
+func (c *contextSQLer) Begin(ctx context.Context, txOps vtxn.TxOptioner) (qet QueryExecTransactioner, err error) {
+	if tx, ok, sameOwner := txFromContextFor(ctx, c.base); ok && sameOwner {
+		return &joinedTx{QueryExecTransactioner: tx}, nil
+	}
+	return c.base.Begin(ctx, txOps)
+}
+
+// Ping pings base
+func (c *contextSQLer) Ping(ctx context.Context) (err error) {
+	return c.base.Ping(ctx)
+}
+
+// Close closes base
+func (c *contextSQLer) Close() error {
+	return c.base.Close()
+}
+
+// joinedTx is a transaction carried by a context, as returned by the Begin of NewContextSQLer. Only the block that started it can end it
+type joinedTx struct {
+	QueryExecTransactioner
+}
+
+// Commit does nothing, as the transaction is committed by the block that started it
+func (t *joinedTx) Commit() error {
+	return nil
+}
+
+// Rollback does not roll back, as the transaction is ended by the block that started it. It returns ErrJoinedTxnRollback for that block to act on
+func (t *joinedTx) Rollback() error {
+	return ErrJoinedTxnRollback
+}
@@ -0,0 +1,176 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vsql
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestTxnContext(t *testing.T) {
+	ctx := context.Background()
+	qet := &QueryExecTransactionerMock{}
+	qet.On("Exec", mock.Anything, nil).
+		Twice().
+		Return(nil, nil)
+	qet.On("Commit").
+		Once().
+		Return(nil)
+	sqlerMock := &SQLerMock{}
+	sqlerMock.On("Begin", ctx, nil).
+		Once().
+		Return(qet, nil)
+	sqlerMock.On("Exec", ctx, nil).
+		Once().
+		Return(nil, nil)
+	db := NewContextQueryExecer(sqlerMock)
+
+	_, err := db.Exec(ctx, nil)
+	assert.NoError(t, err)
+	err = TxnContext(sqlerMock, ctx, nil, func(ctx context.Context) (commit bool, err error) {
+		_, err = db.Exec(ctx, nil)
+		assert.NoError(t, err)
+		err = TxnContext(sqlerMock, ctx, nil, func(ctx context.Context) (commit bool, err error) {
+			_, err = db.Exec(ctx, nil)
+			return true, err
+		})
+		return true, err
+	})
+
+	assert.NoError(t, err)
+	sqlerMock.AssertExpectations(t)
+	qet.AssertExpectations(t)
+}
+
+func TestTxnContext_JoinedRollback(t *testing.T) {
+	ctx := context.Background()
+	qet := &QueryExecTransactionerMock{}
+	qet.On("Rollback").
+		Once().
+		Return(nil)
+	sqlerMock := &SQLerMock{}
+	sqlerMock.On("Begin", ctx, nil).
+		Once().
+		Return(qet, nil)
+
+	err := TxnContext(sqlerMock, ctx, nil, func(ctx context.Context) (commit bool, err error) {
+		_, ok := TxFromContext(ctx)
+		assert.True(t, ok)
+		err = TxnContext(sqlerMock, ctx, nil, func(ctx context.Context) (commit bool, err error) {
+			return false, nil
+		})
+		return true, err
+	})
+
+	assert.Equal(t, ErrJoinedTxnRollback, err)
+	sqlerMock.AssertExpectations(t)
+	qet.AssertExpectations(t)
+}
+
+func TestNewContextSQLer(t *testing.T) {
+	ctx := context.Background()
+	qet := &QueryExecTransactionerMock{}
+	qet.On("Exec", mock.Anything, nil).
+		Once().
+		Return(nil, nil)
+	qet.On("Commit").
+		Once().
+		Return(nil)
+	sqlerMock := &SQLerMock{}
+	sqlerMock.On("Begin", ctx, nil).
+		Once().
+		Return(qet, nil)
+	db := NewContextSQLer(sqlerMock)
+	var events []string
+
+	err := TxnContext(sqlerMock, ctx, nil, func(ctx context.Context) (commit bool, err error) {
+		err = Txn(db, ctx, nil, func(tx QueryExecer) (commit bool, err error) {
+			_, err = tx.Exec(ctx, nil)
+			assert.NoError(t, err)
+			assert.NoError(t, OnAfterCommit(tx, func() {
+				events = append(events, "after commit")
+			}))
+			return true, nil
+		})
+		events = append(events, "joined")
+		assert.NoError(t, err)
+
+		err = Txn(db, ctx, nil, func(tx QueryExecer) (commit bool, err error) {
+			return false, nil
+		})
+		assert.Equal(t, ErrJoinedTxnRollback, err)
+		return true, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"joined", "after commit"}, events)
+	sqlerMock.AssertExpectations(t)
+	qet.AssertExpectations(t)
+}
+
+func TestNewContextSQLer_NoTxn(t *testing.T) {
+	ctx := context.Background()
+	qet := &QueryExecTransactionerMock{}
+	qet.On("Commit").
+		Once().
+		Return(nil)
+	sqlerMock := &SQLerMock{}
+	sqlerMock.On("Begin", ctx, nil).
+		Once().
+		Return(qet, nil)
+
+	err := Txn(NewContextSQLer(sqlerMock), ctx, nil, func(tx QueryExecer) (commit bool, err error) {
+		return true, nil
+	})
+
+	assert.NoError(t, err)
+	sqlerMock.AssertExpectations(t)
+	qet.AssertExpectations(t)
+}
+
+func TestTxnContext_OtherSQLer(t *testing.T) {
+	ctx := context.Background()
+	qet := &QueryExecTransactionerMock{}
+	qet.On("Rollback").
+		Once().
+		Return(nil)
+	sqlerMock := &SQLerMock{}
+	sqlerMock.On("Begin", ctx, nil).
+		Once().
+		Return(qet, nil)
+	otherMock := &SQLerMock{}
+	otherMock.On("Exec", mock.Anything, nil).
+		Once().
+		Return(nil, nil)
+
+	err := TxnContext(sqlerMock, ctx, nil, func(ctx context.Context) (commit bool, err error) {
+		// queries for the other database are not run in this transaction
+		_, err = NewContextQueryExecer(otherMock).Exec(ctx, nil)
+		assert.NoError(t, err)
+		return true, TxnContext(otherMock, ctx, nil, func(ctx context.Context) (commit bool, err error) {
+			t.Error("block must not join a transaction of another SQLer")
+			return true, nil
+		})
+	})
+
+	assert.True(t, errors.Is(err, ErrJoinedTxnOtherSQLer))
+	sqlerMock.AssertExpectations(t)
+	otherMock.AssertExpectations(t)
+	qet.AssertExpectations(t)
+}
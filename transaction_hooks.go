@@ -0,0 +1,164 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vsql
+
+import (
+	"errors"
+)
+
+// TransactionHooker is implemented by the transactions handed to the blocks of Txn and TxnNested. It runs code depending on how the transaction ends, such as publishing events only once the changes they describe are committed
+//
+// Hooks run in the order they were registered. Hooks registered in a transaction nested with TxnNested are handed to the transaction it is nested in when it commits, so they only run when the outermost transaction ends
+//
+// A panic in an after commit hook is not recovered, as the transaction has already been committed and cannot be rolled back
+type TransactionHooker interface {
+	// BeforeCommit registers a hook to run just before the transaction commits. If it returns an error, the transaction is rolled back instead and that error is returned by Txn
+	BeforeCommit(hook func() error)
+	// AfterCommit registers a hook to run after the transaction committed
+	AfterCommit(hook func())
+	// AfterRollback registers a hook to run after the transaction was rolled back, or failed to commit
+	AfterRollback(hook func())
+}
+
+// ErrNoTransactionHooks is returned by OnBeforeCommit, OnAfterCommit and OnAfterRollback when given something other than the transaction handed to a Txn or TxnNested block
+var ErrNoTransactionHooks = errors.New("vsql: hooks can only be registered on the transaction handed to a Txn or TxnNested block")
+
+// OnBeforeCommit registers a hook to run just before the transaction commits. See TransactionHooker
+// @param q is the transaction handed to the block of Txn or TxnNested
+// @return err ErrNoTransactionHooks if q is not a TransactionHooker
+func OnBeforeCommit(q QueryExecer, hook func() error) (err error) {
+	hooker, ok := q.(TransactionHooker)
+	if !ok {
+		return ErrNoTransactionHooks
+	}
+	hooker.BeforeCommit(hook)
+	return nil
+}
+
+// OnAfterCommit registers a hook to run after the transaction commits. See TransactionHooker
+// @param q is the transaction handed to the block of Txn or TxnNested
+// @return err ErrNoTransactionHooks if q is not a TransactionHooker
+// @example
+//   err := vsql.Txn(db, ctx, nil, func(tx vsql.QueryExecer) (commit bool, err error) {
+//     ...
+//     err = vsql.OnAfterCommit(tx, func() { cache.Delete(userID) })
+//     return true, err
+//   })
+func OnAfterCommit(q QueryExecer, hook func()) (err error) {
+	hooker, ok := q.(TransactionHooker)
+	if !ok {
+		return ErrNoTransactionHooks
+	}
+	hooker.AfterCommit(hook)
+	return nil
+}
+
+// OnAfterRollback registers a hook to run after the transaction is rolled back, or fails to commit. See TransactionHooker
+// @param q is the transaction handed to the block of Txn or TxnNested
+// @return err ErrNoTransactionHooks if q is not a TransactionHooker
+func OnAfterRollback(q QueryExecer, hook func()) (err error) {
+	hooker, ok := q.(TransactionHooker)
+	if !ok {
+		return ErrNoTransactionHooks
+	}
+	hooker.AfterRollback(hook)
+	return nil
+}
+
+// txHooks holds the hooks registered on a transaction
+type txHooks struct {
+	beforeCommit  []func() error
+	afterCommit   []func()
+	afterRollback []func()
+}
+
+func (h *txHooks) BeforeCommit(hook func() error) {
+	h.beforeCommit = append(h.beforeCommit, hook)
+}
+
+func (h *txHooks) AfterCommit(hook func()) {
+	h.afterCommit = append(h.afterCommit, hook)
+}
+
+func (h *txHooks) AfterRollback(hook func()) {
+	h.afterRollback = append(h.afterRollback, hook)
+}
+
+// runBeforeCommit runs the before commit hooks until one fails
+func (h *txHooks) runBeforeCommit() (err error) {
+	for _, hook := range h.beforeCommit {
+		if err = hook(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// committed runs the after commit hooks, or hands all of the hooks to the parent to run when it ends
+// @param parent is the hooks of the transaction this one is nested in, or nil if this is the outermost transaction
+func (h *txHooks) committed(parent *txHooks) {
+	if parent != nil {
+		parent.beforeCommit = append(parent.beforeCommit, h.beforeCommit...)
+		parent.afterCommit = append(parent.afterCommit, h.afterCommit...)
+		parent.afterRollback = append(parent.afterRollback, h.afterRollback...)
+		return
+	}
+	for _, hook := range h.afterCommit {
+		hook()
+	}
+}
+
+// rolledBack runs the after rollback hooks. Changes rolled back in a nested transaction are gone for good, so these are never handed to the parent
+func (h *txHooks) rolledBack() {
+	for _, hook := range h.afterRollback {
+		hook()
+	}
+}
+
+// UnwrapTxn gets the transaction that was started by the SQLer from the one handed to a Txn or TxnNested block, such as to type-assert it to the driver's own transaction type
+// @param q is the transaction handed to the block of Txn or TxnNested
+// @return tx is the transaction from Begin, or q if it was not handed to a block
+func UnwrapTxn(q QueryExecer) (tx QueryExecer) {
+	switch hooked := q.(type) {
+	case *hookedTx:
+		return hooked.Unwrap()
+	case *hookedNestedTx:
+		return hooked.Unwrap()
+	}
+	return q
+}
+
+// hookedTx is the transaction handed to Txn blocks
+type hookedTx struct {
+	QueryExecTransactioner
+	*txHooks
+}
+
+// Unwrap is the transaction from Begin
+func (t *hookedTx) Unwrap() QueryExecTransactioner {
+	return t.QueryExecTransactioner
+}
+
+// hookedNestedTx is the transaction handed to TxnNested blocks
+type hookedNestedTx struct {
+	QueryExecNestedTransactioner
+	*txHooks
+}
+
+// Unwrap is the transaction from Begin
+func (t *hookedNestedTx) Unwrap() QueryExecNestedTransactioner {
+	return t.QueryExecNestedTransactioner
+}
@@ -0,0 +1,190 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vsql
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTxn_Hooks(t *testing.T) {
+	errBeforeCommit := errors.New("invalid")
+	errCommit := errors.New("connection lost")
+	cases := map[string]struct {
+		commit       bool
+		blockErr     error
+		beforeErr    error
+		commitErr    error
+		expectedErr  error
+		expectedRuns []string
+	}{
+		"commit": {
+			commit:       true,
+			expectedRuns: []string{"before 1", "before 2", "after commit 1", "after commit 2"},
+		},
+		"rollback": {
+			expectedRuns: []string{"after rollback 1", "after rollback 2"},
+		},
+		"before commit failed": {
+			commit:       true,
+			beforeErr:    errBeforeCommit,
+			expectedErr:  errBeforeCommit,
+			expectedRuns: []string{"before 1", "after rollback 1", "after rollback 2"},
+		},
+		"commit failed": {
+			commit:       true,
+			commitErr:    errCommit,
+			expectedErr:  errCommit,
+			expectedRuns: []string{"before 1", "before 2", "after rollback 1", "after rollback 2"},
+		},
+	}
+
+	for caseName, c := range cases {
+		ctx := context.Background()
+		qet := &QueryExecTransactionerMock{}
+		qet.On("Commit").
+			Return(c.commitErr)
+		qet.On("Rollback").
+			Return(nil)
+		sqlerMock := &SQLerMock{}
+		sqlerMock.On("Begin", ctx, nil).
+			Once().
+			Return(qet, nil)
+		var runs []string
+
+		err := Txn(sqlerMock, ctx, nil, func(tx QueryExecer) (commit bool, err error) {
+			assert.NoError(t, OnBeforeCommit(tx, func() error {
+				runs = append(runs, "before 1")
+				return c.beforeErr
+			}))
+			assert.NoError(t, OnBeforeCommit(tx, func() error {
+				runs = append(runs, "before 2")
+				return nil
+			}))
+			for _, name := range []string{"1", "2"} {
+				name := name
+				assert.NoError(t, OnAfterCommit(tx, func() { runs = append(runs, "after commit "+name) }))
+				assert.NoError(t, OnAfterRollback(tx, func() { runs = append(runs, "after rollback "+name) }))
+			}
+			return c.commit, c.blockErr
+		})
+
+		assert.Equal(t, c.expectedErr, err, caseName)
+		assert.Equal(t, c.expectedRuns, runs, caseName)
+	}
+}
+
+func TestTxnNested_HooksDeferredToOutermost(t *testing.T) {
+	ctx := context.Background()
+	inner := &QueryExecNestedTransactionerMock{}
+	inner.On("Commit").
+		Once().
+		Return(nil)
+	rolledBack := &QueryExecNestedTransactionerMock{}
+	rolledBack.On("Rollback").
+		Once().
+		Return(nil)
+	outer := &QueryExecNestedTransactionerMock{}
+	outer.On("Begin", ctx, nil).
+		Once().
+		Return(inner, nil)
+	outer.On("Begin", ctx, nil).
+		Once().
+		Return(rolledBack, nil)
+	outer.On("Commit").
+		Once().
+		Return(nil)
+	sqlerMock := &SQLNesterMock{}
+	sqlerMock.On("Begin", ctx, nil).
+		Once().
+		Return(outer, nil)
+	var runs []string
+
+	err := TxnNested(sqlerMock, ctx, nil, func(tx QueryExecTransactioner) (commit bool, err error) {
+		_ = OnAfterCommit(tx, func() { runs = append(runs, "outer") })
+		err = TxnNested(tx.(TransactionNestedStarter), ctx, nil, func(tx QueryExecTransactioner) (commit bool, err error) {
+			_ = OnAfterCommit(tx, func() { runs = append(runs, "inner") })
+			return true, nil
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, runs, "nested hooks must wait for the outermost commit")
+		err = TxnNested(tx.(TransactionNestedStarter), ctx, nil, func(tx QueryExecTransactioner) (commit bool, err error) {
+			_ = OnAfterCommit(tx, func() { runs = append(runs, "rolled back commit") })
+			_ = OnAfterRollback(tx, func() { runs = append(runs, "rolled back") })
+			return false, nil
+		})
+		assert.NoError(t, err)
+		return true, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rolled back", "outer", "inner"}, runs)
+	outer.AssertExpectations(t)
+	inner.AssertExpectations(t)
+	rolledBack.AssertExpectations(t)
+}
+
+func TestOnAfterCommit_NotInTxn(t *testing.T) {
+	assert.Equal(t, ErrNoTransactionHooks, OnAfterCommit(&QueryExecerMock{}, func() {}))
+}
+
+func TestTxn_AfterCommitHookPanics(t *testing.T) {
+	ctx := context.Background()
+	qet := &QueryExecTransactionerMock{}
+	qet.On("Commit").
+		Once().
+		Return(nil)
+	sqlerMock := &SQLerMock{}
+	sqlerMock.On("Begin", ctx, nil).
+		Once().
+		Return(qet, nil)
+	rolledBack := false
+
+	assert.PanicsWithValue(t, "hook failed", func() {
+		_ = Txn(sqlerMock, ctx, nil, func(tx QueryExecer) (commit bool, err error) {
+			_ = OnAfterCommit(tx, func() { panic("hook failed") })
+			_ = OnAfterRollback(tx, func() { rolledBack = true })
+			return true, nil
+		})
+	})
+
+	// Rollback is not mocked, so calling it would have failed the test
+	qet.AssertExpectations(t)
+	assert.False(t, rolledBack, "a committed transaction must never be rolled back")
+}
+
+func TestUnwrapTxn(t *testing.T) {
+	ctx := context.Background()
+	qet := &QueryExecTransactionerMock{}
+	qet.On("Rollback").
+		Once().
+		Return(nil)
+	sqlerMock := &SQLerMock{}
+	sqlerMock.On("Begin", ctx, nil).
+		Once().
+		Return(qet, nil)
+
+	_ = Txn(sqlerMock, ctx, nil, func(tx QueryExecer) (commit bool, err error) {
+		assert.False(t, tx == QueryExecer(qet), "the block is given a wrapper")
+		assert.True(t, UnwrapTxn(tx) == QueryExecer(qet))
+		return false, nil
+	})
+
+	other := &QueryExecerMock{}
+	assert.True(t, UnwrapTxn(other) == QueryExecer(other))
+}
@@ -0,0 +1,211 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/wojnosystems/vsql/vtxn"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how TxnRetry and TxnNestedRetry re-run a transaction block
+type RetryPolicy struct {
+	// MaxAttempts is the most times the block is run, including the first. Values less than 1 are treated as 1
+	MaxAttempts int
+	// InitialBackoff is how long to wait before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps how long to wait between any two attempts
+	MaxBackoff time.Duration
+	// Multiplier grows the backoff after each retry. Values less than 1 are treated as 1
+	Multiplier float64
+	// Jitter is the fraction, from 0 to 1, of each backoff that is randomized so that competing transactions don't retry in lock-step
+	Jitter float64
+	// Retryable decides whether the error returned by an attempt means the block should be run again. Defaults to IsRetryableError if nil
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy retries deadlocks and serialization failures up to 5 times, waiting 10ms, then 20ms, 40ms and 80ms, each up to half shorter
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 10 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Multiplier:     2,
+	Jitter:         0.5,
+	Retryable:      IsRetryableError,
+}
+
+// retryableSQLStates are the SQLSTATE codes that mean the transaction failed because of other transactions and will likely succeed if run again
+var retryableSQLStates = map[string]bool{
+	// serialization_failure, which MySQL also uses for deadlocks
+	"40001": true,
+	// Postgres' deadlock_detected
+	"40P01": true,
+}
+
+// retryableMySQLErrors are the prefixes of MySQL driver error messages for deadlocks and lock wait timeouts
+var retryableMySQLErrors = []string{"Error 1213", "Error 1205"}
+
+// IsRetryableError is the default RetryPolicy.Retryable. It recognizes deadlocks and serialization failures without depending on any driver:
+//
+//	errors with a SQLState() string method, such as those from pgx and lib/pq, with the SQLSTATE 40001 or 40P01
+//	errors from go-sql-driver/mysql with the numbers 1213 (deadlock) or 1205 (lock wait timeout)
+//
+// Wrapped and joined errors are searched, so a deadlock is still found when the rollback after it also failed. For anything else, write your own and set it on the RetryPolicy
+func IsRetryableError(err error) bool {
+	var stater interface{ SQLState() string }
+	if errors.As(err, &stater) && retryableSQLStates[stater.SQLState()] {
+		return true
+	}
+	return isRetryableMySQLError(err)
+}
+
+// isRetryableMySQLError is true if err, or any error it wraps or joins, has the message of a MySQL deadlock or lock wait timeout
+func isRetryableMySQLError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	for _, prefix := range retryableMySQLErrors {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	switch unwrapper := err.(type) {
+	case interface{ Unwrap() error }:
+		return isRetryableMySQLError(unwrapper.Unwrap())
+	case interface{ Unwrap() []error }:
+		for _, joined := range unwrapper.Unwrap() {
+			if isRetryableMySQLError(joined) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TxnRetry is Txn, but runs the block again in a new transaction when it fails with an error that policy says is retryable, such as a deadlock
+//
+// The whole block is re-run, so it must be safe to repeat: anything it does outside of the database, such as appending to a slice, must be reset at the start of the block
+// @param policy controls how many times and how often the block is retried
+// @return err nil if an attempt succeeded, the first error that is not retryable, ctx.Err() if the context ended while waiting to retry, or an *ErrRetriesExhausted with the error of the last attempt
+// @example
+//   serializable := &vtxn.TxOption{}
+//   serializable.SetIsolationLevel(sql.LevelSerializable)
+//   err := vsql.TxnRetry(db, ctx, serializable, vsql.DefaultRetryPolicy, func(qe vsql.QueryExecer) (commit bool, err error) {
+//     ...
+//   })
+func TxnRetry(s SQLer, ctx context.Context, txOps vtxn.TxOptioner, policy RetryPolicy, block func(t QueryExecer) (commit bool, err error)) (err error) {
+	return retry(ctx, policy, func() error {
+		return Txn(s, ctx, txOps, block)
+	})
+}
+
+// TxnNestedRetry is TxnNested, but runs the block again in a new transaction when it fails with an error that policy says is retryable. See TxnRetry
+func TxnNestedRetry(s TransactionNestedStarter, ctx context.Context, txOps vtxn.TxOptioner, policy RetryPolicy, block func(t QueryExecTransactioner) (commit bool, err error)) (err error) {
+	return retry(ctx, policy, func() error {
+		return TxnNested(s, ctx, txOps, block)
+	})
+}
+
+// retrySleep waits for d, or until ctx ends. Replaced by tests
+var retrySleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryRand returns a number in [0, 1) for jitter. Replaced by tests
+var retryRand = rand.Float64
+
+// retry calls attempt until it succeeds, fails with an error that is not retryable, or runs out of attempts
+func retry(ctx context.Context, policy RetryPolicy, attempt func() error) (err error) {
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryableError
+	}
+	backoff := policy.InitialBackoff
+	for attempts := 1; ; attempts++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		err = attempt()
+		if err == nil || !retryable(err) {
+			return err
+		}
+		if attempts >= policy.MaxAttempts {
+			return &ErrRetriesExhausted{attempts: attempts, err: err}
+		}
+		if err = retrySleep(ctx, policy.jittered(backoff)); err != nil {
+			return err
+		}
+		backoff = policy.next(backoff)
+	}
+}
+
+// jittered randomly shortens the backoff by up to the Jitter fraction
+func (p RetryPolicy) jittered(backoff time.Duration) time.Duration {
+	jitter := p.Jitter
+	if jitter > 1 {
+		jitter = 1
+	}
+	if jitter <= 0 {
+		return backoff
+	}
+	return backoff - time.Duration(float64(backoff)*jitter*retryRand())
+}
+
+// next grows the backoff for the following retry, up to MaxBackoff
+func (p RetryPolicy) next(backoff time.Duration) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	backoff = time.Duration(float64(backoff) * multiplier)
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
+}
+
+// ErrRetriesExhausted is returned by TxnRetry and TxnNestedRetry when every attempt failed with a retryable error
+type ErrRetriesExhausted struct {
+	attempts int
+	err      error
+}
+
+// Attempts is how many times the block was run
+func (e ErrRetriesExhausted) Attempts() int {
+	return e.attempts
+}
+
+// Error satisfies the Error interface and includes the error of the last attempt
+func (e ErrRetriesExhausted) Error() string {
+	return fmt.Sprintf("transaction failed after %d attempts: %s", e.attempts, e.err)
+}
+
+// Unwrap is the error of the last attempt, so errors.Is and errors.As can find it
+func (e ErrRetriesExhausted) Unwrap() error {
+	return e.err
+}
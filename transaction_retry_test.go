@@ -0,0 +1,183 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// sqlStateError is an error with a SQLSTATE, like those from Postgres drivers
+type sqlStateError string
+
+func (e sqlStateError) Error() string {
+	return "sqlstate " + string(e)
+}
+
+func (e sqlStateError) SQLState() string {
+	return string(e)
+}
+
+// stubRetrySleep records the backoffs instead of waiting, until the test ends
+func stubRetrySleep(t *testing.T) (backoffs *[]time.Duration) {
+	backoffs = new([]time.Duration)
+	oldSleep, oldRand := retrySleep, retryRand
+	retrySleep = func(ctx context.Context, d time.Duration) error {
+		*backoffs = append(*backoffs, d)
+		return ctx.Err()
+	}
+	retryRand = func() float64 {
+		return 0.5
+	}
+	t.Cleanup(func() {
+		retrySleep, retryRand = oldSleep, oldRand
+	})
+	return
+}
+
+func TestIsRetryableError(t *testing.T) {
+	cases := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"nil": {
+			err: nil,
+		},
+		"other": {
+			err: errors.New("boom"),
+		},
+		"serialization failure": {
+			err:      sqlStateError("40001"),
+			expected: true,
+		},
+		"postgres deadlock": {
+			err:      sqlStateError("40P01"),
+			expected: true,
+		},
+		"unique violation": {
+			err: sqlStateError("23505"),
+		},
+		"mysql deadlock": {
+			err:      errors.New("Error 1213 (40001): Deadlock found when trying to get lock; try restarting transaction"),
+			expected: true,
+		},
+		"wrapped": {
+			err:      fmt.Errorf("updating users: %w", sqlStateError("40001")),
+			expected: true,
+		},
+		"joined with rollback failure": {
+			err:      errors.Join(sqlStateError("40P01"), &ErrRollbackFailed{err: errors.New("connection lost")}),
+			expected: true,
+		},
+		"mysql joined with rollback failure": {
+			err:      errors.Join(errors.New("Error 1205 (HY000): Lock wait timeout exceeded"), &ErrRollbackFailed{err: errors.New("connection lost")}),
+			expected: true,
+		},
+	}
+
+	for caseName, c := range cases {
+		assert.Equal(t, c.expected, IsRetryableError(c.err), caseName)
+	}
+}
+
+func TestTxnRetry(t *testing.T) {
+	deadlock := sqlStateError("40P01")
+	permanent := errors.New("boom")
+	cases := map[string]struct {
+		errs     []error
+		attempts int
+		backoffs []time.Duration
+		expected error
+	}{
+		"first attempt": {
+			errs:     []error{nil},
+			attempts: 1,
+		},
+		"retried": {
+			errs:     []error{deadlock, deadlock, nil},
+			attempts: 3,
+			backoffs: []time.Duration{7500 * time.Microsecond, 15 * time.Millisecond},
+		},
+		"not retryable": {
+			errs:     []error{deadlock, permanent},
+			attempts: 2,
+			backoffs: []time.Duration{7500 * time.Microsecond},
+			expected: permanent,
+		},
+		"exhausted": {
+			errs:     []error{deadlock, deadlock, deadlock},
+			attempts: 3,
+			backoffs: []time.Duration{7500 * time.Microsecond, 15 * time.Millisecond},
+			expected: &ErrRetriesExhausted{attempts: 3, err: deadlock},
+		},
+	}
+
+	for caseName, c := range cases {
+		backoffs := stubRetrySleep(t)
+		ctx := context.Background()
+		qet := &QueryExecTransactionerMock{}
+		qet.On("Rollback").
+			Return(nil)
+		qet.On("Commit").
+			Return(nil)
+		sqlerMock := &SQLerMock{}
+		sqlerMock.On("Begin", ctx, nil).
+			Return(qet, nil)
+		attempts := 0
+		policy := DefaultRetryPolicy
+		policy.MaxAttempts = 3
+
+		err := TxnRetry(sqlerMock, ctx, nil, policy, func(t QueryExecer) (commit bool, err error) {
+			attempts++
+			return true, c.errs[attempts-1]
+		})
+
+		assert.Equal(t, c.expected, err, caseName)
+		assert.Equal(t, c.attempts, attempts, caseName)
+		assert.Equal(t, c.backoffs, []time.Duration(*backoffs), caseName)
+	}
+}
+
+func TestTxnRetry_ContextCancelled(t *testing.T) {
+	stubRetrySleep(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	qet := &QueryExecTransactionerMock{}
+	qet.On("Rollback").
+		Return(nil)
+	sqlerMock := &SQLerMock{}
+	sqlerMock.On("Begin", ctx, nil).
+		Return(qet, nil)
+	attempts := 0
+
+	err := TxnRetry(sqlerMock, ctx, nil, DefaultRetryPolicy, func(t QueryExecer) (commit bool, err error) {
+		attempts++
+		cancel()
+		return true, sqlStateError("40001")
+	})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryPolicy_next(t *testing.T) {
+	p := RetryPolicy{Multiplier: 3, MaxBackoff: 100 * time.Millisecond}
+	assert.Equal(t, 30*time.Millisecond, p.next(10*time.Millisecond))
+	assert.Equal(t, 100*time.Millisecond, p.next(50*time.Millisecond))
+}
@@ -16,6 +16,7 @@
 package vparam
 
 import (
+	"fmt"
 	"github.com/wojnosystems/vsql/interpolation_strategy"
 	"strings"
 )
@@ -26,6 +27,9 @@ const AppenderPlaceholder = "?"
 type appender struct {
 	query
 	parameters []interface{}
+
+	// emptySlice is what to do when a slice parameter has no elements
+	emptySlice EmptySliceBehavior
 }
 
 // NewAppend creates a new appending Parameterer in which you can repeatedly append values to the parameter list as desired
@@ -59,14 +63,68 @@ func (p *appender) Append(value interface{}) {
 	p.parameters = append(p.parameters, value)
 }
 
+// SetEmptySliceBehavior changes what happens when a slice parameter has no elements
+func (p *appender) SetEmptySliceBehavior(b EmptySliceBehavior) {
+	p.emptySlice = b
+}
+
+// Interpolate replaces the question mark (?) placeholders with those of the strategy. Slice values are expanded into one placeholder per element
+// Question marks inside of literals and comments, escaped question marks (??) and, when the strategy is for Postgres, the ?| and ?& operators are not placeholders
 func (p *appender) Interpolate(sqlQuery string, strategy interpolation_strategy.InterpolateStrategy) (interpolatedSQLQuery string, params []interface{}, err error) {
-	interpolatedQuery := p.SQLQueryInterpolated(strategy)
-	if len(p.parameters) != strings.Count(interpolatedQuery, strategy.InsertPlaceholderIntoSQL()) {
-		return "", []interface{}{}, ErrParameterPlaceholderMismatch
+	tokens, err := tokenize(sqlQuery, placeholderPositional, syntaxFor(strategy))
+	if err != nil {
+		return "", []interface{}{}, err
+	}
+	placeholderCount := 0
+	for _, tok := range tokens {
+		if tok.kind == tokenPositional {
+			placeholderCount++
+		}
+	}
+	if len(p.parameters) != placeholderCount {
+		return "", []interface{}{}, newErrPlaceholderMismatch(sqlQuery, tokens, p.parameters)
 	}
-	return interpolatedQuery, p.parameters, nil
+	sb := strings.Builder{}
+	params = make([]interface{}, 0, len(p.parameters))
+	placeholderIndex := 0
+	for _, tok := range tokens {
+		if tok.kind == tokenPositional {
+			var expanded []interface{}
+			expanded, err = writeExpandedPlaceholders(&sb, strategy, p.parameters[placeholderIndex], p.emptySlice, fmt.Sprintf("%s number %d", AppenderPlaceholder, placeholderIndex+1))
+			if err != nil {
+				return "", []interface{}{}, err
+			}
+			placeholderIndex++
+			params = append(params, expanded...)
+		} else {
+			sb.WriteString(tok.value)
+		}
+	}
+	return sb.String(), params, nil
 }
 
+// SQLQueryInterpolated replaces the question mark (?) placeholders with those of the strategy. Question marks inside of literals and comments are left alone
+// Placeholders for values already appended as slices are expanded
 func (p *appender) SQLQueryInterpolated(strategy interpolation_strategy.InterpolateStrategy) string {
-	return strings.ReplaceAll(p.query.SQLQueryUnInterpolated(), AppenderPlaceholder, strategy.InsertPlaceholderIntoSQL())
+	tokens, _ := tokenize(p.query.SQLQueryUnInterpolated(), placeholderPositional, syntaxFor(strategy))
+	sb := strings.Builder{}
+	placeholderIndex := 0
+	for _, tok := range tokens {
+		if tok.kind == tokenPositional {
+			var value interface{}
+			if placeholderIndex < len(p.parameters) {
+				value = p.parameters[placeholderIndex]
+			}
+			placeholderIndex++
+			_, _ = writeExpandedPlaceholders(&sb, strategy, value, EmptySliceIsNull, "")
+		} else {
+			sb.WriteString(tok.value)
+		}
+	}
+	return sb.String()
+}
+
+// String shows the query with the values inlined, for debugging only. See DebugSQL
+func (p *appender) String() string {
+	return debugString(p)
 }
@@ -16,7 +16,9 @@
 package vparam
 
 import (
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/wojnosystems/vsql/interpolation_strategy"
 	"testing"
 )
 
@@ -39,6 +41,30 @@ func TestAppendParameter_Interpolate(t *testing.T) {
 			queryExpected:      "select * from mytable",
 			parametersExpected: []interface{}{},
 		},
+		"slice expansion": {
+			queryIn:            "select * from mytable where value1 in (?) and value2 = ?",
+			parametersIn:       []interface{}{[]string{"a", "b"}, "puppy"},
+			queryExpected:      "select * from mytable where value1 in (?, ?) and value2 = ?",
+			parametersExpected: []interface{}{"a", "b", "puppy"},
+		},
+		"escaped question mark": {
+			queryIn:            "select * from mytable where data ?? 'key' and value2 = ?",
+			parametersIn:       []interface{}{"puppy"},
+			queryExpected:      "select * from mytable where data ? 'key' and value2 = ?",
+			parametersExpected: []interface{}{"puppy"},
+		},
+		"escaped json operators": {
+			queryIn:            "select * from mytable where data ??| array['a'] and data ??& array['b'] and value2 = ?||'x'",
+			parametersIn:       []interface{}{"puppy"},
+			queryExpected:      "select * from mytable where data ?| array['a'] and data ?& array['b'] and value2 = ?||'x'",
+			parametersExpected: []interface{}{"puppy"},
+		},
+		"question mark in literal": {
+			queryIn:            "select * from mytable where value1 = 'why?' and value2 = ?",
+			parametersIn:       []interface{}{"puppy"},
+			queryExpected:      "select * from mytable where value1 = 'why?' and value2 = ?",
+			parametersExpected: []interface{}{"puppy"},
+		},
 	}
 	for caseName, c := range cases {
 		ap := NewAppend(c.queryIn)
@@ -53,7 +79,7 @@ func TestAppendParameter_Interpolate(t *testing.T) {
 			t.Errorf(`%s: Query Expected: "%s" but got "%s"`, caseName, c.queryExpected, actualQuery)
 		}
 		for i := range c.parametersExpected {
-			if actualParams[i] != c.parametersExpected[i] {
+			if !assert.ObjectsAreEqual(c.parametersExpected[i], actualParams[i]) {
 				t.Errorf(`%s: Param[%d] Expected: "%v" but got "%v"`, caseName, i, c.parametersExpected[i], actualParams[i])
 			}
 		}
@@ -65,3 +91,106 @@ func TestAppendParameter_Interpolate(t *testing.T) {
 		assert.Equal(t, actualParams, apwdActualParams)
 	}
 }
+
+func TestAppendParameter_InterpolateEmptySlice(t *testing.T) {
+	ap := NewAppendWithData("select * from mytable where value1 = ? and value2 in (?)", "puppy", []int{})
+	_, _, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testStrategyDefault)
+	assert.Equal(t, &ErrEmptySlice{placeholder: "? number 2"}, err)
+
+	ap.SetEmptySliceBehavior(EmptySliceIsNull)
+	actualQuery, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testOrdinalStrategy{})
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from mytable where value1 = $1 and value2 in ($2)", actualQuery)
+	assert.Equal(t, []interface{}{"puppy", nil}, actualParams)
+}
+
+func TestAppendParameter_InterpolateOrdinal(t *testing.T) {
+	ap := NewAppendWithData("select * from mytable where value1 = ? and note = 'why?' and value2 = ?", 5, "puppy")
+	actualQuery, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testOrdinalStrategy{})
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from mytable where value1 = $1 and note = 'why?' and value2 = $2", actualQuery)
+	assert.Equal(t, []interface{}{5, "puppy"}, actualParams)
+}
+
+func TestAppendParameter_InterpolateBackslash(t *testing.T) {
+	cases := map[string]struct {
+		strategy      interpolation_strategy.InterpolateStrategy
+		queryIn       string
+		queryExpected string
+	}{
+		"postgres": {
+			strategy:      interpolation_strategy.NewDollarOrdinal(),
+			queryIn:       `select * from files where path = 'C:\' and id = ? and note = E'it\'s?'`,
+			queryExpected: `select * from files where path = 'C:\' and id = $1 and note = E'it\'s?'`,
+		},
+		"sql server": {
+			strategy:      interpolation_strategy.NewAtPOrdinal(),
+			queryIn:       `select "C:\" from files where path = 'C:\' and id = ?`,
+			queryExpected: `select "C:\" from files where path = 'C:\' and id = @p1`,
+		},
+		"mysql": {
+			strategy:      interpolation_strategy.NewQuestionMark(),
+			queryIn:       `select * from files where note = 'it\'s?' and id = ?`,
+			queryExpected: `select * from files where note = 'it\'s?' and id = ?`,
+		},
+	}
+	for caseName, c := range cases {
+		ap := NewAppendWithData(c.queryIn, 5)
+		actualQuery, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), c.strategy)
+		assert.NoError(t, err, caseName)
+		assert.Equal(t, c.queryExpected, actualQuery, caseName)
+		assert.Equal(t, []interface{}{5}, actualParams, caseName)
+	}
+}
+
+func TestAppendParameter_InterpolateMySQLComments(t *testing.T) {
+	ap := NewAppendWithData("select a$b$c # why?\n, ? from t", 5)
+	actualQuery, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), interpolation_strategy.NewQuestionMark())
+	assert.NoError(t, err)
+	assert.Equal(t, "select a$b$c # why?\n, ? from t", actualQuery)
+	assert.Equal(t, []interface{}{5}, actualParams)
+}
+
+func TestAppendParameter_InterpolateQuestionOperators(t *testing.T) {
+	ap := NewAppendWithData("select * from mytable where data ?| array['a'] and data ?& array['b'] and value2 = ?||'x'", "puppy")
+	actualQuery, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), interpolation_strategy.NewDollarOrdinal())
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from mytable where data ?| array['a'] and data ?& array['b'] and value2 = $1||'x'", actualQuery)
+	assert.Equal(t, []interface{}{"puppy"}, actualParams)
+
+	// MySQL has no such operators, so these are placeholders followed by bitwise operators
+	ap = NewAppendWithData("select * from mytable where flags = ?|4 and id = ?&7", 1, 2)
+	actualQuery, actualParams, err = ap.Interpolate(ap.SQLQueryUnInterpolated(), interpolation_strategy.NewQuestionMark())
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from mytable where flags = ?|4 and id = ?&7", actualQuery)
+	assert.Equal(t, []interface{}{1, 2}, actualParams)
+}
+
+func TestAppendParameter_InterpolateMismatch(t *testing.T) {
+	cases := map[string]struct {
+		queryIn       string
+		parametersIn  []interface{}
+		expected      *ErrPlaceholderMismatch
+		expectedError string
+	}{
+		"too few parameters": {
+			queryIn:       "select * from mytable\nwhere value1 = ? and value2 = ?",
+			parametersIn:  []interface{}{5},
+			expected:      &ErrPlaceholderMismatch{Placeholders: 2, Parameters: 1, Line: 2, Column: 31, Offset: 52},
+			expectedError: "interpolation failed: 2 placeholders but 1 parameters, placeholder 2 at line 2, column 31 (offset 52) has no parameter",
+		},
+		"too many parameters": {
+			queryIn:       "select * from mytable where value1 = ? and value2 = '?'",
+			parametersIn:  []interface{}{5, "puppy"},
+			expected:      &ErrPlaceholderMismatch{Placeholders: 1, Parameters: 2, extraParameterType: "string"},
+			expectedError: "interpolation failed: 1 placeholders but 2 parameters, parameter 2 (string) has no placeholder",
+		},
+	}
+	for caseName, c := range cases {
+		ap := NewAppendWithData(c.queryIn, c.parametersIn...)
+		_, _, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testStrategyDefault)
+		assert.Equal(t, c.expected, err, caseName)
+		assert.EqualError(t, err, c.expectedError, caseName)
+		assert.True(t, errors.Is(err, ErrParameterPlaceholderMismatch), caseName)
+	}
+}
@@ -0,0 +1,227 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vparam
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"github.com/wojnosystems/vsql/interpolation_strategy"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DebugDialect describes how a database writes literal values, so that DebugSQL can inline them
+type DebugDialect struct {
+	// Dialect is the name of the database, e.g. interpolation_strategy.DialectMySQL, so that the query is parsed as that database does. Empty means standard SQL
+	Dialect string
+	// BackslashEscapes is true if backslashes in strings must be escaped, as MySQL does by default
+	BackslashEscapes bool
+	// BytesPrefix and BytesSuffix surround the hexadecimal encoding of []byte values, e.g. X' and '
+	BytesPrefix string
+	BytesSuffix string
+	// True and False are the literals for booleans
+	True  string
+	False string
+	// TimeLayout is the time.Time layout for times, which are then quoted like strings
+	TimeLayout string
+}
+
+var (
+	// DebugStandard writes literals as the SQL standard says to and is used when printing a query with %v
+	DebugStandard = DebugDialect{
+		BytesPrefix: "X'",
+		BytesSuffix: "'",
+		True:        "TRUE",
+		False:       "FALSE",
+		TimeLayout:  "2006-01-02 15:04:05.999999999Z07:00",
+	}
+	// DebugMySQL writes literals for MySQL and MariaDB
+	DebugMySQL = DebugDialect{
+		Dialect:          interpolation_strategy.DialectMySQL,
+		BackslashEscapes: true,
+		BytesPrefix:      "X'",
+		BytesSuffix:      "'",
+		True:             "TRUE",
+		False:            "FALSE",
+		TimeLayout:       "2006-01-02 15:04:05.999999",
+	}
+	// DebugPostgres writes literals for Postgres
+	DebugPostgres = DebugDialect{
+		Dialect:     interpolation_strategy.DialectPostgres,
+		BytesPrefix: `'\x`,
+		BytesSuffix: "'::bytea",
+		True:        "TRUE",
+		False:       "FALSE",
+		TimeLayout:  "2006-01-02 15:04:05.999999Z07:00",
+	}
+	// DebugSQLServer writes literals for Microsoft SQL Server
+	DebugSQLServer = DebugDialect{
+		Dialect:     interpolation_strategy.DialectSQLServer,
+		BytesPrefix: "0x",
+		True:        "1",
+		False:       "0",
+		TimeLayout:  "2006-01-02T15:04:05.9999999Z07:00",
+	}
+	// DebugOracle writes literals for Oracle
+	DebugOracle = DebugDialect{
+		Dialect:     interpolation_strategy.DialectOracle,
+		BytesPrefix: "HEXTORAW('",
+		BytesSuffix: "')",
+		True:        "1",
+		False:       "0",
+		TimeLayout:  "2006-01-02 15:04:05.999999999",
+	}
+	// DebugSQLite writes literals for SQLite
+	DebugSQLite = DebugDialect{
+		Dialect:     interpolation_strategy.DialectSQLite,
+		BytesPrefix: "X'",
+		BytesSuffix: "'",
+		True:        "1",
+		False:       "0",
+		TimeLayout:  "2006-01-02 15:04:05.999999999-07:00",
+	}
+)
+
+// debugMarker surrounds the index of each parameter in the interpolated query so that DebugSQL can find them. NUL does not appear in real queries
+const debugMarker = "\x00"
+
+// DebugSQL interpolates the query and then replaces each placeholder with the literal value it would have been sent with.
+//
+// THIS IS FOR DEBUGGING ONLY. Never execute the result: the escaping is only good enough to make log messages and test failures readable.
+// Use it to log what actually ran when a query fails
+// @param q is any Queryer, such as a Namer or an Appender
+// @param dialect is how to write the values, e.g. DebugMySQL
+// @return debugSQL the query with the values inlined, or an empty string if an error was returned
+// @return err any error from interpolating the query
+func DebugSQL(q Queryer, dialect DebugDialect) (debugSQL string, err error) {
+	interpolated, params, err := q.Interpolate(q.SQLQueryUnInterpolated(), &debugStrategy{dialect: dialect.Dialect})
+	if err != nil {
+		return "", err
+	}
+	parts := strings.Split(interpolated, debugMarker)
+	sb := strings.Builder{}
+	for i, part := range parts {
+		if i%2 == 0 {
+			sb.WriteString(part)
+			continue
+		}
+		index, convErr := strconv.Atoi(part)
+		if convErr != nil || index >= len(params) {
+			// Parameterer returned fewer parameters than placeholders, so show where they were missing
+			sb.WriteString("/* missing parameter */")
+			continue
+		}
+		sb.WriteString(dialect.Literal(params[index]))
+	}
+	return sb.String(), nil
+}
+
+// debugString is used by the String methods of the Queryers in this package so %v shows the query as it would run
+func debugString(q Queryer) string {
+	s, err := DebugSQL(q, DebugStandard)
+	if err != nil {
+		return fmt.Sprintf("%s /* %s */", q.SQLQueryUnInterpolated(), err)
+	}
+	return s
+}
+
+// debugStrategy marks each placeholder with its parameter index
+type debugStrategy struct {
+	dialect string
+	count   int
+}
+
+func (s *debugStrategy) InsertPlaceholderIntoSQL() string {
+	s.count++
+	return debugMarker + strconv.Itoa(s.count-1) + debugMarker
+}
+
+func (s *debugStrategy) Dialect() string {
+	return s.dialect
+}
+
+func (s *debugStrategy) ReusesPlaceholders() {
+}
+
+// Literal writes the value as a SQL literal in this dialect. nil and nil pointers are NULL, driver.Valuers are converted first and other types are written with fmt as strings
+func (d DebugDialect) Literal(value interface{}) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return "NULL"
+		}
+		v, err := valuer.Value()
+		if err != nil {
+			return fmt.Sprintf("/* %s */", err)
+		}
+		value = v
+	}
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return d.quote(v)
+	case []byte:
+		if v == nil {
+			return "NULL"
+		}
+		return d.BytesPrefix + strings.ToUpper(hex.EncodeToString(v)) + d.BytesSuffix
+	case bool:
+		if v {
+			return d.True
+		}
+		return d.False
+	case time.Time:
+		return d.quote(v.Format(d.TimeLayout))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	// named types, such as type ID int64, are written as the type they are built on
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return d.Literal(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return d.Literal(rv.Float())
+	case reflect.Bool:
+		return d.Literal(rv.Bool())
+	case reflect.String:
+		return d.quote(rv.String())
+	}
+	return d.quote(fmt.Sprint(value))
+}
+
+// quote surrounds the string with single quotes, escaping as the dialect requires
+func (d DebugDialect) quote(s string) string {
+	if d.BackslashEscapes {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,102 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vparam
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+type testID uint64
+
+func TestDebugDialect_Literal(t *testing.T) {
+	when := time.Date(2019, 3, 4, 5, 6, 7, 800000000, time.UTC)
+	name := "bob"
+	cases := map[string]struct {
+		valueIn  interface{}
+		dialect  DebugDialect
+		expected string
+	}{
+		"nil":                {valueIn: nil, dialect: DebugMySQL, expected: "NULL"},
+		"nil pointer":        {valueIn: (*string)(nil), dialect: DebugMySQL, expected: "NULL"},
+		"pointer":            {valueIn: &name, dialect: DebugMySQL, expected: "'bob'"},
+		"int":                {valueIn: -5, dialect: DebugMySQL, expected: "-5"},
+		"named uint":         {valueIn: testID(5), dialect: DebugMySQL, expected: "5"},
+		"float":              {valueIn: 1.5, dialect: DebugMySQL, expected: "1.5"},
+		"string mysql":       {valueIn: `it's a \ `, dialect: DebugMySQL, expected: `'it''s a \\ '`},
+		"string postgres":    {valueIn: `it's a \ `, dialect: DebugPostgres, expected: `'it''s a \ '`},
+		"bytes mysql":        {valueIn: []byte{0xde, 0xad}, dialect: DebugMySQL, expected: "X'DEAD'"},
+		"bytes postgres":     {valueIn: []byte{0xde, 0xad}, dialect: DebugPostgres, expected: `'\xDEAD'::bytea`},
+		"bytes sql server":   {valueIn: []byte{0xde, 0xad}, dialect: DebugSQLServer, expected: "0xDEAD"},
+		"bool postgres":      {valueIn: true, dialect: DebugPostgres, expected: "TRUE"},
+		"bool sql server":    {valueIn: false, dialect: DebugSQLServer, expected: "0"},
+		"time mysql":         {valueIn: when, dialect: DebugMySQL, expected: "'2019-03-04 05:06:07.8'"},
+		"time postgres":      {valueIn: when, dialect: DebugPostgres, expected: "'2019-03-04 05:06:07.8Z'"},
+		"valuer":             {valueIn: sql.NullInt64{Int64: 3, Valid: true}, dialect: DebugMySQL, expected: "3"},
+		"null valuer":        {valueIn: sql.NullString{}, dialect: DebugMySQL, expected: "NULL"},
+		"nil pointer valuer": {valueIn: (*sql.NullString)(nil), dialect: DebugMySQL, expected: "NULL"},
+		"anything else":      {valueIn: struct{ A int }{A: 1}, dialect: DebugMySQL, expected: "'{1}'"},
+	}
+	for caseName, c := range cases {
+		assert.Equal(t, c.expected, c.dialect.Literal(c.valueIn), caseName)
+	}
+}
+
+func TestDebugSQL(t *testing.T) {
+	n := NewNamedWithData("select * from t where name = :name and id in (:ids) and note = ':nope' and other = :name",
+		map[string]interface{}{"name": "o'neil", "ids": []int{1, 2}})
+	actual, err := DebugSQL(n, DebugPostgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from t where name = 'o''neil' and id in (1, 2) and note = ':nope' and other = 'o''neil'", actual)
+	assert.Equal(t, actual, fmt.Sprint(n))
+
+	a := NewAppendWithData("select * from t where a = ? and b = ?", nil, true)
+	actual, err = DebugSQL(a, DebugSQLServer)
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from t where a = NULL and b = 1", actual)
+
+	_, err = DebugSQL(NewNamed("select :missing"), DebugMySQL)
+	assert.Equal(t, &ErrMissingNamedParam{name: "missing"}, err)
+	assert.Equal(t, `select :missing /* named parameter "missing" was not set to a value */`, fmt.Sprint(NewNamed("select :missing")))
+}
+
+func TestDebugSQL_Dialect(t *testing.T) {
+	n := NewNamedWithData(`select 'C:\' as dir, :name`, map[string]interface{}{"name": "bob"})
+	actual, err := DebugSQL(n, DebugPostgres)
+	assert.NoError(t, err)
+	assert.Equal(t, `select 'C:\' as dir, 'bob'`, actual)
+
+	n = NewNamedWithData(`select 'it\'s :nope', :name`, map[string]interface{}{"name": "bob"})
+	actual, err = DebugSQL(n, DebugMySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, `select 'it\'s :nope', 'bob'`, actual)
+}
+
+func TestMatchDebugSQL(t *testing.T) {
+	m := &mock.Mock{}
+	m.On("Query", MatchDebugSQL(DebugStandard, "select 'bob'")).
+		Once()
+	m.MethodCalled("Query", NewNamedWithData("select :name", map[string]interface{}{"name": "bob"}))
+	m.AssertExpectations(t)
+
+	matched, _ := m.On("Query", MatchDebugSQL(DebugStandard, "select 'alice'")).Arguments.
+		Diff([]interface{}{NewNamedWithData("select :name", map[string]interface{}{"name": "bob"})})
+	assert.NotEmpty(t, matched)
+}
@@ -0,0 +1,188 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vparam
+
+import (
+	"github.com/wojnosystems/vsql/interpolation_strategy"
+	"strings"
+)
+
+// Fragment is a piece of SQL that carries its own parameters, such as a single condition of a WHERE clause.
+// Fragments can be joined together with And, Or, List and Join, and are placed into a query by using them as the value of a parameter:
+//   where := vparam.And(
+//     vparam.NewFragment("age > ?", 21),
+//     vparam.NewNamedFragment("name = :name", map[string]interface{}{"name": "bob"}),
+//   )
+//   q := vparam.NewAppendWithData("select * from users where ? order by name", where)
+//   q.SQLQueryInterpolated(mysql) // select * from users where (age > ?) AND (name = ?) order by name
+//
+// The values of the fragment are passed to the driver in the order they appear, after the values before it in the parent query and before those after it.
+// Names used inside of named fragments are only visible to that fragment, so they never collide with those of the parent or of other fragments
+type Fragment struct {
+	pieces []fragmentPiece
+}
+
+// fragmentPiece is either SQL text added by a join, or SQL with placeholders that has not been parsed yet
+type fragmentPiece struct {
+	sql string
+	// source is the SQL and values given to NewFragment or NewNamedFragment
+	source *fragmentSource
+}
+
+// fragmentSource is the SQL of a fragment, kept until it is interpolated, as where its literals end depends on the database
+type fragmentSource struct {
+	sql   string
+	style placeholderStyle
+	// values are those of a NewFragment, in order
+	values []interface{}
+	// data are those of a NewNamedFragment, by name
+	data map[string]interface{}
+}
+
+// NewFragment creates a fragment with question mark (?) placeholders, like NewAppendWithData
+// @param sql is the piece of SQL with a ? for each value
+// @param values are the values for the placeholders, in order. Slices are expanded, as with Appender
+// @return the fragment. If the number of values does not match the number of placeholders, the error is returned when the parent query is interpolated
+func NewFragment(sql string, values ...interface{}) *Fragment {
+	return &Fragment{pieces: []fragmentPiece{{source: &fragmentSource{sql: sql, style: placeholderPositional, values: values}}}}
+}
+
+// NewNamedFragment creates a fragment with :named placeholders, like NewNamedWithData
+// @param sql is the piece of SQL with :named placeholders
+// @param data are the values for the names used in sql. Slices are expanded, as with Namer
+// @return the fragment. If a name has no value, the error is returned when the parent query is interpolated
+func NewNamedFragment(sql string, data map[string]interface{}) *Fragment {
+	return &Fragment{pieces: []fragmentPiece{{source: &fragmentSource{sql: sql, style: placeholderNamed, data: data}}}}
+}
+
+// Join combines the fragments into one, with the separator between each
+func Join(separator string, fragments ...*Fragment) *Fragment {
+	return join(separator, false, fragments)
+}
+
+// And joins the fragments with AND, wrapping each in parentheses so that ORs inside them group correctly
+// With no fragments, this is 1=1, which is always true, so it can always be used in a WHERE clause
+func And(fragments ...*Fragment) *Fragment {
+	if len(fragments) == 0 {
+		return NewFragment("1=1")
+	}
+	return join(" AND ", true, fragments)
+}
+
+// Or joins the fragments with OR, wrapping each in parentheses so that ANDs inside them group correctly
+// With no fragments, this is 1=0, which is always false, as nothing matches none of the conditions
+func Or(fragments ...*Fragment) *Fragment {
+	if len(fragments) == 0 {
+		return NewFragment("1=0")
+	}
+	return join(" OR ", true, fragments)
+}
+
+// List joins the fragments with commas, such as for the columns of a SELECT or the assignments of an UPDATE
+func List(fragments ...*Fragment) *Fragment {
+	return join(listSeparator, false, fragments)
+}
+
+func join(separator string, parenthesize bool, fragments []*Fragment) *Fragment {
+	f := &Fragment{}
+	parenthesize = parenthesize && len(fragments) > 1
+	for i, part := range fragments {
+		if i != 0 {
+			f.pieces = append(f.pieces, fragmentPiece{sql: separator})
+		}
+		if parenthesize {
+			f.pieces = append(f.pieces, fragmentPiece{sql: "("})
+		}
+		f.pieces = append(f.pieces, part.pieces...)
+		if parenthesize {
+			f.pieces = append(f.pieces, fragmentPiece{sql: ")"})
+		}
+	}
+	return f
+}
+
+// Queryer turns the fragment into a complete query that can be passed to Query, Exec, etc.
+func (f *Fragment) Queryer() Queryer {
+	return NewAppendWithData(AppenderPlaceholder, f)
+}
+
+// String shows the fragment with the values inlined, for debugging only. See DebugSQL
+func (f *Fragment) String() string {
+	return debugString(f.Queryer())
+}
+
+// writeFragment writes the SQL of the fragment, with placeholders for its values
+// @return expanded the values to pass to the driver for the placeholders that were written
+func writeFragment(sb *strings.Builder, strategy interpolation_strategy.InterpolateStrategy, f *Fragment, emptySlice EmptySliceBehavior) (expanded []interface{}, err error) {
+	syn := syntaxFor(strategy)
+	for _, piece := range f.pieces {
+		if piece.source == nil {
+			sb.WriteString(piece.sql)
+			continue
+		}
+		var values []interface{}
+		values, err = piece.source.write(sb, strategy, syn, emptySlice)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, values...)
+	}
+	return
+}
+
+// write parses the SQL of the fragment with the syntax of the database and writes it, with placeholders for its values
+// @return expanded the values to pass to the driver for the placeholders that were written
+func (s *fragmentSource) write(sb *strings.Builder, strategy interpolation_strategy.InterpolateStrategy, syn syntax, emptySlice EmptySliceBehavior) (expanded []interface{}, err error) {
+	tokens, err := tokenize(s.sql, s.style, syn)
+	if err != nil {
+		return nil, err
+	}
+	if s.style == placeholderPositional {
+		placeholderCount := 0
+		for _, tok := range tokens {
+			if tok.kind == tokenPositional {
+				placeholderCount++
+			}
+		}
+		if placeholderCount != len(s.values) {
+			return nil, newErrPlaceholderMismatch(s.sql, tokens, s.values)
+		}
+	}
+	placeholderIndex := 0
+	for _, tok := range tokens {
+		var value interface{}
+		switch tok.kind {
+		case tokenText:
+			sb.WriteString(tok.value)
+			continue
+		case tokenPositional:
+			value = s.values[placeholderIndex]
+			placeholderIndex++
+		case tokenNamed:
+			var ok bool
+			if value, ok = s.data[tok.value]; !ok {
+				return nil, &ErrMissingNamedParam{name: tok.value}
+			}
+		}
+		var values []interface{}
+		values, err = writeExpandedPlaceholders(sb, strategy, value, emptySlice, "in fragment")
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, values...)
+	}
+	return
+}
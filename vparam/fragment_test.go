@@ -0,0 +1,115 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vparam
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wojnosystems/vsql/interpolation_strategy"
+	"testing"
+)
+
+func TestFragment_Appender(t *testing.T) {
+	where := And(
+		NewFragment("age > ?", 21),
+		Or(
+			NewNamedFragment("name = :name", map[string]interface{}{"name": "bob"}),
+			NewNamedFragment("nickname = :name", map[string]interface{}{"name": "bobby"}),
+		),
+		NewFragment("id in (?)", []int{1, 2}),
+	)
+	q := NewAppendWithData("select * from users where tenant = ? and ? order by ?", 9, where, NewFragment("name"))
+	actualQuery, actualParams, err := q.Interpolate(q.SQLQueryUnInterpolated(), &testOrdinalStrategy{})
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from users where tenant = $1 and (age > $2) AND ((name = $3) OR (nickname = $4)) AND (id in ($5, $6)) order by name", actualQuery)
+	assert.Equal(t, []interface{}{9, 21, "bob", "bobby", 1, 2}, actualParams)
+	assert.Equal(t, actualQuery, q.SQLQueryInterpolated(&testOrdinalStrategy{}))
+}
+
+func TestFragment_Namer(t *testing.T) {
+	set := List(
+		NewNamedFragment("name = :name", map[string]interface{}{"name": "bob"}),
+		NewFragment("age = ?", 5),
+	)
+	q := NewNamedWithData("update users set :set where name = :name and :set_again", map[string]interface{}{
+		"set":       set,
+		"name":      "alice",
+		"set_again": NewFragment("1=1"),
+	})
+	actualQuery, actualParams, err := q.Interpolate(q.SQLQueryUnInterpolated(), &testOrdinalStrategy{})
+	assert.NoError(t, err)
+	assert.Equal(t, "update users set name = $1, age = $2 where name = $3 and 1=1", actualQuery)
+	assert.Equal(t, []interface{}{"bob", 5, "alice"}, actualParams)
+}
+
+func TestFragment_ReusedName(t *testing.T) {
+	q := NewNamedWithData("select :f union select :f", map[string]interface{}{"f": NewFragment("?", 1)})
+	actualQuery, actualParams, err := q.Interpolate(q.SQLQueryUnInterpolated(), &testOrdinalStrategy{})
+	assert.NoError(t, err)
+	assert.Equal(t, "select $1 union select $2", actualQuery)
+	assert.Equal(t, []interface{}{1, 1}, actualParams)
+}
+
+func TestFragment_Dialect(t *testing.T) {
+	f := NewFragment(`path = 'C:\' and id = ?`, 1)
+	q := NewAppendWithData("select * from files where ?", f)
+	actualQuery, actualParams, err := q.Interpolate(q.SQLQueryUnInterpolated(), interpolation_strategy.NewDollarOrdinal())
+	assert.NoError(t, err)
+	assert.Equal(t, `select * from files where path = 'C:\' and id = $1`, actualQuery)
+	assert.Equal(t, []interface{}{1}, actualParams)
+
+	f = NewFragment(`note = 'why\'? ' and id = ?`, 1)
+	q = NewAppendWithData("select * from files where ?", f)
+	actualQuery, actualParams, err = q.Interpolate(q.SQLQueryUnInterpolated(), interpolation_strategy.NewQuestionMark())
+	assert.NoError(t, err)
+	assert.Equal(t, `select * from files where note = 'why\'? ' and id = ?`, actualQuery)
+	assert.Equal(t, []interface{}{1}, actualParams)
+}
+
+func TestFragment_Empty(t *testing.T) {
+	assert.Equal(t, "1=1", And().String())
+	assert.Equal(t, "1=0", Or().String())
+	assert.Equal(t, "a = 1", And(NewFragment("a = ?", 1)).String())
+	assert.Equal(t, "a = 1, b = 'x'", Join(", ", NewFragment("a = ?", 1), NewFragment("b = ?", "x")).String())
+}
+
+func TestFragment_Errors(t *testing.T) {
+	cases := map[string]struct {
+		fragment *Fragment
+		expected error
+	}{
+		"mismatch": {
+			fragment: NewFragment("a = ? and b = ?", 1),
+			expected: &ErrPlaceholderMismatch{Placeholders: 2, Parameters: 1, Line: 1, Column: 15, Offset: 14},
+		},
+		"missing": {
+			fragment: NewNamedFragment("a = :a", map[string]interface{}{}),
+			expected: &ErrMissingNamedParam{name: "a"},
+		},
+		"joined": {
+			fragment: And(NewFragment("a = 1"), NewFragment("b = ?")),
+			expected: &ErrPlaceholderMismatch{Placeholders: 1, Parameters: 0, Line: 1, Column: 5, Offset: 4},
+		},
+		"empty slice": {
+			fragment: NewFragment("a in (?)", []int{}),
+			expected: &ErrEmptySlice{placeholder: "in fragment"},
+		},
+	}
+	for caseName, c := range cases {
+		q := c.fragment.Queryer()
+		_, _, err := q.Interpolate(q.SQLQueryUnInterpolated(), &testStrategyDefault)
+		assert.Equal(t, c.expected, err, caseName)
+	}
+}
@@ -48,23 +48,88 @@ type Parameterer interface {
 	Interpolate(sqlQuery string, strategy interpolation_strategy.InterpolateStrategy) (interpolatedSQLQuery string, params []interface{}, err error)
 }
 
+// SliceExpander is a Parameterer that expands slice and array values into one placeholder per element, so "IN (:ids)" works with a slice
+type SliceExpander interface {
+	// SetEmptySliceBehavior changes what happens when a slice parameter has no elements. The default is EmptySliceIsError
+	SetEmptySliceBehavior(b EmptySliceBehavior)
+}
+
 // Appender is a type of Parameterer that is simply a list of parameters stuck into a SQL string
+// Appending a slice (other than []byte) expands its placeholder into one placeholder per element
 type Appender interface {
 	Queryer
+	SliceExpander
 	// Adds a parameter to the list of variables to parameterize. Values appended will be passed to Query/Exec in the order you called Append
 	Append(value interface{})
 }
 
 // Namer is a type of Parameterer that is a SQL-string with a collection of named keys paired with values
 // Queries should be written with :named keys, which are prefixed with a colon (:) and consist only of a-zA-Z0-9_ and must not start with a number
+// Setting a name to a slice (other than []byte) expands its placeholder into one placeholder per element
 type Namer interface {
 	Queryer
+	SliceExpander
 	Set(name string, value interface{})
+	// Names lists the names of every value that has been Set, in no particular order
+	Names() []string
+	// SetStrict turns strict mode on or off. In strict mode, Interpolate checks the query with Validate first, so every missing and unused name is reported as an *ErrNamedParams
+	// Otherwise, Interpolate stops at the first missing name and ignores unused values. Strict mode is off by default
+	SetStrict(strict bool)
 }
 
 // ErrParameterPlaceholderMismatch is returned when Interpolation is performed, but there are more or fewer placeholders than there is data to put in those parameter placeholders
+// Appenders return an *ErrPlaceholderMismatch with the details, which matches this with errors.Is
 var ErrParameterPlaceholderMismatch = errors.New("interpolation failed: the number of provided parameters doesn't match the number of placeholders")
 
+// ErrPlaceholderMismatch says exactly which placeholder or parameter of an Appender could not be matched up
+// errors.Is(err, ErrParameterPlaceholderMismatch) is true for this error
+type ErrPlaceholderMismatch struct {
+	// Placeholders is the number of placeholders in the query
+	Placeholders int
+	// Parameters is the number of parameters provided
+	Parameters int
+	// Line, Column and Offset locate the first placeholder without a parameter, as in ErrParse. They are 0 when there are too many parameters instead
+	Line   int
+	Column int
+	Offset int
+	// extraParameterType is the type of the first parameter without a placeholder, when there are too many parameters
+	extraParameterType string
+}
+
+// newErrPlaceholderMismatch locates the first unmatched placeholder or parameter
+func newErrPlaceholderMismatch(sqlQuery string, tokens []token, parameters []interface{}) *ErrPlaceholderMismatch {
+	e := &ErrPlaceholderMismatch{Parameters: len(parameters)}
+	for _, tok := range tokens {
+		if tok.kind != tokenPositional {
+			continue
+		}
+		if e.Placeholders == len(parameters) {
+			e.Offset = tok.offset
+			e.Line, e.Column = lineColumn(sqlQuery, tok.offset)
+		}
+		e.Placeholders++
+	}
+	if e.Placeholders < len(parameters) {
+		e.extraParameterType = fmt.Sprintf("%T", parameters[e.Placeholders])
+	}
+	return e
+}
+
+// Error satisfies the Error interface and points at the first placeholder or parameter that has no partner
+func (e ErrPlaceholderMismatch) Error() string {
+	if e.Placeholders > e.Parameters {
+		return fmt.Sprintf(`interpolation failed: %d placeholders but %d parameters, placeholder %d at line %d, column %d (offset %d) has no parameter`,
+			e.Placeholders, e.Parameters, e.Parameters+1, e.Line, e.Column, e.Offset)
+	}
+	return fmt.Sprintf(`interpolation failed: %d placeholders but %d parameters, parameter %d (%s) has no placeholder`,
+		e.Placeholders, e.Parameters, e.Placeholders+1, e.extraParameterType)
+}
+
+// Is allows errors.Is(err, ErrParameterPlaceholderMismatch) to keep working
+func (e ErrPlaceholderMismatch) Is(target error) bool {
+	return target == ErrParameterPlaceholderMismatch
+}
+
 // ErrMissingNamedParam is a custom error message so we can indicate which key was used that didn't exist
 type ErrMissingNamedParam struct {
 	name string
@@ -74,3 +139,53 @@ type ErrMissingNamedParam struct {
 func (e ErrMissingNamedParam) Error() string {
 	return fmt.Sprintf(`named parameter "%s" was not set to a value`, e.name)
 }
+
+// ErrUnusedNamedParam indicates that a value was provided for a name that the query does not use. It is reported as part of ErrNamedParams
+type ErrUnusedNamedParam struct {
+	name string
+}
+
+// Error satisfies the Error interface and says which key was set but never used
+func (e ErrUnusedNamedParam) Error() string {
+	return fmt.Sprintf(`named parameter "%s" was set to a value but is not used by the query`, e.name)
+}
+
+// ErrNotStruct is returned by SetStruct, and by Interpolate for queries from NewNamedFromStruct, when the value is not a struct or a non-nil pointer to one
+type ErrNotStruct struct {
+	value interface{}
+}
+
+// Error satisfies the Error interface and says what was given instead of a struct
+func (e ErrNotStruct) Error() string {
+	return fmt.Sprintf(`a struct or a non-nil pointer to one is required to set named parameters, got %T`, e.value)
+}
+
+// ErrEmptySlice is returned when a slice parameter has no elements and the Parameterer is using EmptySliceIsError
+type ErrEmptySlice struct {
+	placeholder string
+}
+
+// Error satisfies the Error interface and says which parameter was empty
+func (e ErrEmptySlice) Error() string {
+	return fmt.Sprintf(`parameter %s is an empty slice, which cannot be expanded into a list of placeholders`, e.placeholder)
+}
+
+// ErrParse is returned when the SQL query cannot be split into text and placeholders, such as when a colon is not followed by a name
+// The location of the problem is reported in a few ways to make it easy to find in large queries
+type ErrParse struct {
+	// Reason describes what was wrong
+	Reason string
+	// Offset is the 0-based byte offset into the query at which the problem starts
+	Offset int
+	// Line is the 1-based line number of Offset
+	Line int
+	// Column is the 1-based column, in characters, of Offset within Line
+	Column int
+	// Snippet is the part of the query surrounding Offset
+	Snippet string
+}
+
+// Error satisfies the Error interface and points at exactly where the query went wrong
+func (e ErrParse) Error() string {
+	return fmt.Sprintf(`unable to parse query at line %d, column %d (offset %d): %s near "%s"`, e.Line, e.Column, e.Offset, e.Reason, e.Snippet)
+}
@@ -17,7 +17,6 @@ package vparam
 
 import (
 	"github.com/wojnosystems/vsql/interpolation_strategy"
-	"regexp"
 	"strings"
 )
 
@@ -29,6 +28,15 @@ type named struct {
 
 	// parameters represents the values passed to the object through repeated calls to Set and/or from initialization
 	parameters map[string]interface{}
+
+	// emptySlice is what to do when a slice parameter has no elements
+	emptySlice EmptySliceBehavior
+
+	// strict causes Interpolate to validate the names before interpolating
+	strict bool
+
+	// err is the problem with the struct given to NewNamedFromStruct, if any. It is returned by Interpolate
+	err error
 }
 
 // NewNamed creates a new named query
@@ -81,62 +89,123 @@ func (p *named) Set(key string, value interface{}) {
 	p.parameters[key] = value
 }
 
+// SetEmptySliceBehavior changes what happens when a slice parameter has no elements
+func (p *named) SetEmptySliceBehavior(b EmptySliceBehavior) {
+	p.emptySlice = b
+}
+
+// Names lists the names of every value that has been Set
+func (p *named) Names() []string {
+	names := make([]string, 0, len(p.parameters))
+	for name := range p.parameters {
+		names = append(names, name)
+	}
+	return names
+}
+
+// SetStrict turns on or off reporting every missing and unused name when interpolating
+func (p *named) SetStrict(strict bool) {
+	p.strict = strict
+}
+
+// Interpolate replaces the named placeholders with those of the strategy and orders the values to match.
+// Slice values are expanded into one placeholder per element.
+// If the strategy is an interpolation_strategy.ReusableInterpolateStrategy, names used more than once share placeholders and their values are only passed once
+// In strict mode, every missing and unused name is reported as an *ErrNamedParams, otherwise the first missing name is reported as an *ErrMissingNamedParam
 func (p *named) Interpolate(sqlQuery string, strategy interpolation_strategy.InterpolateStrategy) (interpolatedSQLQuery string, params []interface{}, err error) {
-	interpolatedSQLQuery = p.SQLQueryInterpolated(strategy)
-	orderedNamedParameters := collectPlaceholderNames(sqlQuery)
-	orderedParams := make([]interface{}, 0, len(p.parameters))
-	for _, key := range orderedNamedParameters {
-		if value, ok := p.parameters[key]; !ok {
+	if p.err != nil {
+		return "", nil, p.err
+	}
+	syn := syntaxFor(strategy)
+	if p.strict {
+		if err = validateNamed(sqlQuery, p.Names(), syn); err != nil {
+			return
+		}
+	}
+	tokens, err := tokenize(sqlQuery, placeholderNamed, syn)
+	if err != nil {
+		return
+	}
+	w := newNamedWriter(strategy, len(p.parameters))
+	for _, tok := range tokens {
+		if tok.kind != tokenNamed {
+			w.sb.WriteString(tok.value)
+			continue
+		}
+		if value, ok := p.parameters[tok.value]; !ok {
 			// named parameter found, but no mapping for its value was found
-			err = &ErrMissingNamedParam{name: key}
+			err = &ErrMissingNamedParam{name: tok.value}
 			return
-		} else {
-			orderedParams = append(orderedParams, value)
+		} else if err = w.writePlaceholders(tok.value, value, p.emptySlice); err != nil {
+			return "", nil, err
 		}
 	}
-	return interpolatedSQLQuery, orderedParams, err
+	return w.sb.String(), w.params, err
 }
 
-// collectPlaceholderNames converts the sql-string with the named parameters into the driver-specific format (depending on the strategy)
-//
-// @param unInterpolatedSQLQuery is the query from the Queryer object's SQLQueryUnInterpolated(). This should be the query as provided by the developer with placeholders for the named variables intead of question marks or ordinal parameters
-// @return orderedNamedParameters is the order in which parameters were encountered in the unInterpolatedSQLQuery. These are just tokens and not the actual values
-func collectPlaceholderNames(unInterpolatedSQLQuery string) (orderedNamedParameters []string) {
-	replacementCount := strings.Count(unInterpolatedSQLQuery, NamedPlaceholderPrefix)
-	orderedNamedParameters = make([]string, 0, replacementCount)
-	parts := strings.Split(unInterpolatedSQLQuery, NamedPlaceholderPrefix)
-	if len(parts) > 1 {
-		for i := 1; i < len(parts); i++ {
-			match := namedParameterName.FindStringIndex(parts[i])
-			paramName := parts[i][match[0]:match[1]]
-			orderedNamedParameters = append(orderedNamedParameters, paramName)
-		}
+// namedWriter builds the interpolated query, remembering the placeholders written for each name so that strategies supporting reuse can write them again
+type namedWriter struct {
+	sb       strings.Builder
+	strategy interpolation_strategy.InterpolateStrategy
+	// reusable is true if the strategy supports reuse
+	reusable bool
+	params   []interface{}
+	// written is the placeholders that were written for each name, e.g. "$2, $3" for a slice
+	written map[string]string
+}
+
+func newNamedWriter(strategy interpolation_strategy.InterpolateStrategy, capacity int) *namedWriter {
+	w := &namedWriter{
+		strategy: strategy,
+		params:   make([]interface{}, 0, capacity),
+	}
+	if _, ok := strategy.(interpolation_strategy.ReusableInterpolateStrategy); ok {
+		w.reusable = true
+		w.written = make(map[string]string)
 	}
-	return
+	return w
+}
+
+// writePlaceholders writes the placeholders for the named parameter and records its values
+func (w *namedWriter) writePlaceholders(name string, value interface{}, emptySlice EmptySliceBehavior) error {
+	// fragments are SQL, not just values, so they have to be written out every time
+	_, isFragment := value.(*Fragment)
+	reuse := w.reusable && !isFragment
+	if placeholders, ok := w.written[name]; reuse && ok {
+		w.sb.WriteString(placeholders)
+		return nil
+	}
+	start := w.sb.Len()
+	expanded, err := writeExpandedPlaceholders(&w.sb, w.strategy, value, emptySlice, NamedPlaceholderPrefix+name)
+	if err != nil {
+		return err
+	}
+	if reuse {
+		w.written[name] = w.sb.String()[start:]
+	}
+	w.params = append(w.params, expanded...)
+	return nil
 }
 
 // SQLQueryInterpolated converts the stored query string from named placeholders to a interpolation-strategy-specific string, ready to be passed to a database driver
 // @param strategy is how to insert placeholder for the driver-specific format
-// @return interpolatedSQLQuery is the query with the InterpolateStrategy parameters instead of the names of the parameter placeholders
+// @return interpolatedSQLQuery is the query with the InterpolateStrategy parameters instead of the names of the parameter placeholders. Names already set to slices are expanded
 func (p *named) SQLQueryInterpolated(strategy interpolation_strategy.InterpolateStrategy) string {
-	sb := strings.Builder{}
-	parts := strings.Split(p.SQLQueryUnInterpolated(), NamedPlaceholderPrefix)
-	sb.WriteString(parts[0])
-	if len(parts) > 1 {
-		for i := 1; i < len(parts); i++ {
-			match := namedParameterName.FindStringIndex(parts[i])
+	// malformed placeholders are left in the query as-is. Interpolate reports them as errors
+	tokens, _ := tokenize(p.SQLQueryUnInterpolated(), placeholderNamed, syntaxFor(strategy))
+	w := newNamedWriter(strategy, len(p.parameters))
+	for _, tok := range tokens {
+		if tok.kind == tokenNamed {
 			// remove the name, replace with the strategy
-			sb.WriteString(strategy.InsertPlaceholderIntoSQL())
-			sb.WriteString(parts[i][match[1]:])
+			_ = w.writePlaceholders(tok.value, p.parameters[tok.value], EmptySliceIsNull)
+		} else {
+			w.sb.WriteString(tok.value)
 		}
 	}
-	return sb.String()
+	return w.sb.String()
 }
 
-// namedParameterName is a pre-compiled regular expression used to find the name of the named parameter AFTER the marker has been identified with colon (:)
-var namedParameterName *regexp.Regexp
-
-func init() {
-	// looks for the value after the ":". Strings will have already been split, so this value will always be at the beginning of the part. This finds the ":name" where name is the key identifier for the named parameter
-	namedParameterName = regexp.MustCompile("^([a-zA-Z_][a-zA-Z0-9_]*)")
+// String shows the query with the values inlined, for debugging only. See DebugSQL
+func (p *named) String() string {
+	return debugString(p)
 }
@@ -16,7 +16,9 @@
 package vparam
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/wojnosystems/vsql/interpolation_strategy"
 	"testing"
 )
 
@@ -45,6 +47,42 @@ func TestNamedParameter_Interpolate(t *testing.T) {
 			queryExpected:      "select * from my_table",
 			parametersExpected: []interface{}{},
 		},
+		"colon in literal": {
+			queryIn:            "select * from my_table where opens = '12:30' and value1 = :pet",
+			parametersIn:       map[string]interface{}{"pet": "puppy"},
+			queryExpected:      "select * from my_table where opens = '12:30' and value1 = ?",
+			parametersExpected: []interface{}{"puppy"},
+		},
+		"postgres cast": {
+			queryIn:            "select :age::int, value1::text from my_table",
+			parametersIn:       map[string]interface{}{"age": 5},
+			queryExpected:      "select ?::int, value1::text from my_table",
+			parametersExpected: []interface{}{5},
+		},
+		"comments": {
+			queryIn:            "select * -- :nope\nfrom my_table /* :nada */ where value1 = :pet",
+			parametersIn:       map[string]interface{}{"pet": "puppy"},
+			queryExpected:      "select * -- :nope\nfrom my_table /* :nada */ where value1 = ?",
+			parametersExpected: []interface{}{"puppy"},
+		},
+		"slice expansion": {
+			queryIn:            "select * from my_table where id in (:ids) and value1 = :pet",
+			parametersIn:       map[string]interface{}{"ids": []int{1, 2, 3}, "pet": "puppy"},
+			queryExpected:      "select * from my_table where id in (?, ?, ?) and value1 = ?",
+			parametersExpected: []interface{}{1, 2, 3, "puppy"},
+		},
+		"bytes are not expanded": {
+			queryIn:            "select * from my_table where hash = :hash",
+			parametersIn:       map[string]interface{}{"hash": []byte{1, 2, 3}},
+			queryExpected:      "select * from my_table where hash = ?",
+			parametersExpected: []interface{}{[]byte{1, 2, 3}},
+		},
+		"dollar quoted": {
+			queryIn:            "select $fn$ return :nope; $fn$, :pet",
+			parametersIn:       map[string]interface{}{"pet": "puppy"},
+			queryExpected:      "select $fn$ return :nope; $fn$, ?",
+			parametersExpected: []interface{}{"puppy"},
+		},
 	}
 	for caseName, c := range cases {
 		ap := NewNamed(c.queryIn)
@@ -59,7 +97,7 @@ func TestNamedParameter_Interpolate(t *testing.T) {
 			t.Errorf(`%s: Query Expected: "%s" but got "%s"`, caseName, c.queryExpected, actualQuery)
 		}
 		for i := range c.parametersExpected {
-			if actualParams[i] != c.parametersExpected[i] {
+			if !assert.ObjectsAreEqual(c.parametersExpected[i], actualParams[i]) {
 				t.Errorf(`%s: Param[%d] Expected: "%v" but got "%v"`, caseName, i, c.parametersExpected[i], actualParams[i])
 			}
 		}
@@ -72,6 +110,103 @@ func TestNamedParameter_Interpolate(t *testing.T) {
 	}
 }
 
+func TestNamedParameter_InterpolateMySQL(t *testing.T) {
+	ap := NewNamedWithData("select a$b$c # what:ever\nfrom t where id = :id", map[string]interface{}{"id": 5})
+	actualQuery, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), interpolation_strategy.NewQuestionMark())
+	assert.NoError(t, err)
+	assert.Equal(t, "select a$b$c # what:ever\nfrom t where id = ?", actualQuery)
+	assert.Equal(t, []interface{}{5}, actualParams)
+}
+
+func TestNamedParameter_InterpolateArraySlice(t *testing.T) {
+	cases := map[string]struct {
+		queryIn       string
+		queryExpected string
+	}{
+		"bounds": {
+			queryIn:       "select arr[1:2] from t where id = :id",
+			queryExpected: "select arr[1:2] from t where id = $1",
+		},
+		"names are columns": {
+			queryIn:       "select arr[:lo:hi] from t where id = :id",
+			queryExpected: "select arr[:lo:hi] from t where id = $1",
+		},
+	}
+	for caseName, c := range cases {
+		ap := NewNamedWithData(c.queryIn, map[string]interface{}{"id": 5})
+		actualQuery, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), interpolation_strategy.NewDollarOrdinal())
+		assert.NoError(t, err, caseName)
+		assert.Equal(t, c.queryExpected, actualQuery, caseName)
+		assert.Equal(t, []interface{}{5}, actualParams, caseName)
+	}
+}
+
+func TestNamedParameter_InterpolateParseError(t *testing.T) {
+	ap := NewNamedWithData("select * from my_table where value1 = :pet and value2 = : ", map[string]interface{}{"pet": "puppy"})
+	_, _, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testStrategyDefault)
+	if assert.IsType(t, &ErrParse{}, err) {
+		parseErr := err.(*ErrParse)
+		assert.Equal(t, 56, parseErr.Offset)
+		assert.Equal(t, 1, parseErr.Line)
+		assert.Equal(t, 57, parseErr.Column)
+	}
+}
+
+func TestNamedParameter_InterpolateSliceOrdinal(t *testing.T) {
+	ap := NewNamedWithData("select * from my_table where id in (:ids) and value1 = :pet", map[string]interface{}{"ids": [2]string{"a", "b"}, "pet": "puppy"})
+	actualQuery, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testOrdinalStrategy{})
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from my_table where id in ($1, $2) and value1 = $3", actualQuery)
+	assert.Equal(t, []interface{}{"a", "b", "puppy"}, actualParams)
+	assert.Equal(t, actualQuery, ap.SQLQueryInterpolated(&testOrdinalStrategy{}))
+}
+
+func TestNamedParameter_InterpolateReuseOrdinal(t *testing.T) {
+	ap := NewNamedWithData("select * from a where tenant = :tenant and id in (:ids) union select * from b where tenant = :tenant and id in (:ids) and name = :name",
+		map[string]interface{}{"tenant": 9, "ids": []int{1, 2}, "name": "bob"})
+	actualQuery, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testOrdinalStrategy{})
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from a where tenant = $1 and id in ($2, $3) union select * from b where tenant = $1 and id in ($2, $3) and name = $4", actualQuery)
+	assert.Equal(t, []interface{}{9, 1, 2, "bob"}, actualParams)
+	assert.Equal(t, actualQuery, ap.SQLQueryInterpolated(&testOrdinalStrategy{}))
+
+	// strategies that cannot reuse placeholders still get one value per placeholder
+	actualQuery, actualParams, err = ap.Interpolate(ap.SQLQueryUnInterpolated(), &testStrategyDefault)
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from a where tenant = ? and id in (?, ?) union select * from b where tenant = ? and id in (?, ?) and name = ?", actualQuery)
+	assert.Equal(t, []interface{}{9, 1, 2, 9, 1, 2, "bob"}, actualParams)
+}
+
+func TestNamedParameter_InterpolateReuseStrategy(t *testing.T) {
+	ap := NewNamedWithData("select :a, :b, :a", map[string]interface{}{"a": 1, "b": 2})
+	strategy := interpolation_strategy.NewDollarOrdinal()
+	assert.Equal(t, "select $1, $2, $1", ap.SQLQueryInterpolated(strategy))
+
+	// the strategy was not reset, so its numbers carry on, but a name always repeats the placeholder it was given
+	actualQuery, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), strategy)
+	assert.NoError(t, err)
+	assert.Equal(t, "select $3, $4, $3", actualQuery)
+	assert.Equal(t, []interface{}{1, 2}, actualParams)
+
+	// Oracle binds by position, so every placeholder gets its own value
+	actualQuery, actualParams, err = ap.Interpolate(ap.SQLQueryUnInterpolated(), interpolation_strategy.NewColonOrdinal())
+	assert.NoError(t, err)
+	assert.Equal(t, "select :1, :2, :3", actualQuery)
+	assert.Equal(t, []interface{}{1, 2, 1}, actualParams)
+}
+
+func TestNamedParameter_InterpolateEmptySlice(t *testing.T) {
+	ap := NewNamedWithData("select * from my_table where id in (:ids)", map[string]interface{}{"ids": []int{}})
+	_, _, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testStrategyDefault)
+	assert.Equal(t, &ErrEmptySlice{placeholder: ":ids"}, err)
+
+	ap.SetEmptySliceBehavior(EmptySliceIsNull)
+	actualQuery, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testStrategyDefault)
+	assert.NoError(t, err)
+	assert.Equal(t, "select * from my_table where id in (?)", actualQuery)
+	assert.Equal(t, []interface{}{nil}, actualParams)
+}
+
 type testStrategy struct {
 }
 
@@ -80,3 +215,16 @@ func (m *testStrategy) InsertPlaceholderIntoSQL() string {
 }
 
 var testStrategyDefault = testStrategy{}
+
+// testOrdinalStrategy numbers the placeholders like Postgres does
+type testOrdinalStrategy struct {
+	count int
+}
+
+func (m *testOrdinalStrategy) InsertPlaceholderIntoSQL() string {
+	m.count++
+	return fmt.Sprintf("$%d", m.count)
+}
+
+func (m *testOrdinalStrategy) ReusesPlaceholders() {
+}
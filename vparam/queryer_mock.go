@@ -36,3 +36,13 @@ func (q *QueryerMock) SQLQueryUnInterpolated() string {
 	a := q.Called()
 	return a.Get(0).(string)
 }
+
+// MatchDebugSQL matches Queryer arguments by the query they would run, as rendered by DebugSQL, so mock expectations can be written as real SQL
+// @example
+//   m.On("Query", ctx, vparam.MatchDebugSQL(vparam.DebugStandard, "select * from users where id = 5"))
+func MatchDebugSQL(dialect DebugDialect, expected string) interface{} {
+	return mock.MatchedBy(func(q Queryer) bool {
+		actual, err := DebugSQL(q, dialect)
+		return err == nil && actual == expected
+	})
+}
@@ -0,0 +1,85 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vparam
+
+import (
+	"database/sql/driver"
+	"github.com/wojnosystems/vsql/interpolation_strategy"
+	"reflect"
+	"strings"
+)
+
+// EmptySliceBehavior is what to do when a slice parameter has no elements. "IN ()" is not valid SQL, so something has to give
+type EmptySliceBehavior int
+
+const (
+	// EmptySliceIsError causes Interpolate to return ErrEmptySlice. This is the default
+	EmptySliceIsError EmptySliceBehavior = iota
+	// EmptySliceIsNull replaces the empty slice with a single NULL value. "x IN (NULL)" matches nothing, which is usually what an empty list means
+	EmptySliceIsNull
+)
+
+// listSeparator is placed between the placeholders of an expanded slice
+const listSeparator = ", "
+
+var driverValuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// expandValue converts a value into the list of values its placeholder represents.
+// Slices and arrays are expanded into their elements so they can be used with IN (...).
+// []byte, byte arrays and anything implementing driver.Valuer are sent to the driver as a single value
+// @return values the flattened values. This is just value if it is not a list
+// @return isList true if value was expanded
+func expandValue(value interface{}) (values []interface{}, isList bool) {
+	if value == nil {
+		return []interface{}{value}, false
+	}
+	t := reflect.TypeOf(value)
+	if (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) ||
+		t.Elem().Kind() == reflect.Uint8 ||
+		t.Implements(driverValuerType) {
+		return []interface{}{value}, false
+	}
+	v := reflect.ValueOf(value)
+	values = make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values, true
+}
+
+// writeExpandedPlaceholders writes one placeholder per value, separated by commas, handling empty slices as configured
+// Fragments are written in place of the placeholder, along with their own placeholders
+// @param placeholder describes the placeholder for error messages
+// @return expanded the values to pass to the driver for the placeholders that were written
+func writeExpandedPlaceholders(sb *strings.Builder, strategy interpolation_strategy.InterpolateStrategy, value interface{}, emptySlice EmptySliceBehavior, placeholder string) (expanded []interface{}, err error) {
+	if f, ok := value.(*Fragment); ok && f != nil {
+		return writeFragment(sb, strategy, f, emptySlice)
+	}
+	expanded, isList := expandValue(value)
+	if isList && len(expanded) == 0 {
+		if emptySlice != EmptySliceIsNull {
+			return nil, &ErrEmptySlice{placeholder: placeholder}
+		}
+		expanded = []interface{}{nil}
+	}
+	for i := range expanded {
+		if i != 0 {
+			sb.WriteString(listSeparator)
+		}
+		sb.WriteString(strategy.InsertPlaceholderIntoSQL())
+	}
+	return
+}
@@ -0,0 +1,50 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vparam
+
+import (
+	"database/sql/driver"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testValuerSlice []string
+
+func (v testValuerSlice) Value() (driver.Value, error) {
+	return "{a,b}", nil
+}
+
+func TestExpandValue(t *testing.T) {
+	cases := map[string]struct {
+		valueIn        interface{}
+		valuesExpected []interface{}
+		isList         bool
+	}{
+		"scalar":       {valueIn: 5, valuesExpected: []interface{}{5}},
+		"nil":          {valueIn: nil, valuesExpected: []interface{}{nil}},
+		"slice":        {valueIn: []int64{1, 2}, valuesExpected: []interface{}{int64(1), int64(2)}, isList: true},
+		"array":        {valueIn: [2]string{"a", "b"}, valuesExpected: []interface{}{"a", "b"}, isList: true},
+		"empty":        {valueIn: []interface{}{}, valuesExpected: []interface{}{}, isList: true},
+		"bytes":        {valueIn: []byte("ab"), valuesExpected: []interface{}{[]byte("ab")}},
+		"byte array":   {valueIn: [2]byte{1, 2}, valuesExpected: []interface{}{[2]byte{1, 2}}},
+		"driver value": {valueIn: testValuerSlice{"a", "b"}, valuesExpected: []interface{}{testValuerSlice{"a", "b"}}},
+	}
+	for caseName, c := range cases {
+		values, isList := expandValue(c.valueIn)
+		assert.Equal(t, c.valuesExpected, values, caseName)
+		assert.Equal(t, c.isList, isList, caseName)
+	}
+}
@@ -0,0 +1,84 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vparam
+
+import (
+	"github.com/wojnosystems/vsql/vstruct"
+	"reflect"
+)
+
+// NewNamedFromStruct creates a new named query with the parameters taken from the `db:"name"` tagged fields of a struct
+// This makes inserting and updating domain objects a one-liner
+// @vparam query is the SQL you wish to execute. When you want to insert a value, use the colon (:) to denote the start of a named parameter. e.g. ":name"
+// @vparam v is the struct, or a pointer to the struct, to read. See SetStruct for how fields are read
+// @return the Queryer-conforming parameterer. If v is not a struct or a non-nil pointer to one, Interpolate returns an *ErrNotStruct
+// @example
+//   type User struct { Name string `db:"name"`; Age int `db:"age"` }
+//   query: "insert into users (name, age) values (:name, :age)"
+//   v: User{Name: "bob", Age: 21}
+//   inserts: a user named "bob" who is 21 years old
+func NewNamedFromStruct(query string, v interface{}) Namer {
+	n := &named{
+		query:      *newQueryWithSQL(query),
+		parameters: make(map[string]interface{}),
+	}
+	n.err = SetStruct(n, v)
+	return n
+}
+
+// SetStruct calls n.Set for each `db:"name"` tagged field of the struct v
+//
+// Fields are read as follows:
+//   `db:"-"` and untagged fields are skipped
+//   `db:"name,omitempty"` fields are skipped when they are the zero value for their type, which lets the SQL use defaults
+//   untagged embedded structs, and pointers to structs, have their fields read as if they were part of v. If the pointer is nil, its fields are set to nil
+//   pointer fields are dereferenced, and nil pointers are set to nil (NULL). Types implementing driver.Valuer are passed as-is
+//
+// @return err *ErrNotStruct if v is not a struct or a non-nil pointer to one, in which case nothing is Set
+func SetStruct(n Namer, v interface{}) (err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return &ErrNotStruct{value: v}
+	}
+	for _, field := range vstruct.Fields(rv.Type()) {
+		fv, ok := vstruct.FieldByIndex(rv, field.Index)
+		if !ok {
+			if !field.OmitEmpty {
+				n.Set(field.Name, nil)
+			}
+			continue
+		}
+		if field.OmitEmpty && fv.IsZero() {
+			continue
+		}
+		n.Set(field.Name, structFieldValue(fv))
+	}
+	return nil
+}
+
+// structFieldValue dereferences pointers so that the value, rather than the pointer, is handed to the Namer
+func structFieldValue(fv reflect.Value) interface{} {
+	for fv.Kind() == reflect.Ptr && !fv.Type().Implements(driverValuerType) {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+	return fv.Interface()
+}
@@ -0,0 +1,92 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vparam
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testPetBase struct {
+	ID int `db:"id"`
+}
+
+type testPetOwner struct {
+	Owner string `db:"owner"`
+}
+
+type testPet struct {
+	testPetBase
+	*testPetOwner
+	Name  string  `db:"name"`
+	Age   *int    `db:"age"`
+	Color string  `db:"color,omitempty"`
+	Notes *string `db:"notes,omitempty"`
+}
+
+func TestNewNamedFromStruct(t *testing.T) {
+	age := 5
+	pet := &testPet{
+		testPetBase: testPetBase{ID: 7},
+		Name:        "puppy",
+		Age:         &age,
+	}
+	ap := NewNamedFromStruct("insert into pets (id, name, age, owner) values (:id, :name, :age, :owner)", pet)
+	actualQuery, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testStrategyDefault)
+	assert.NoError(t, err)
+	assert.Equal(t, "insert into pets (id, name, age, owner) values (?, ?, ?, ?)", actualQuery)
+	assert.Equal(t, []interface{}{7, "puppy", 5, nil}, actualParams)
+}
+
+func TestNewNamedFromStruct_OmitEmpty(t *testing.T) {
+	ap := NewNamedFromStruct("select :color", testPet{})
+	_, _, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testStrategyDefault)
+	assert.Equal(t, &ErrMissingNamedParam{name: "color"}, err)
+
+	ap = NewNamedFromStruct("select :color, :age", testPet{Color: "brown"})
+	_, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testStrategyDefault)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"brown", nil}, actualParams)
+}
+
+type testPetTagged struct {
+	testPetBase `db:"base"`
+	Name        string `db:"name"`
+}
+
+func TestNewNamedFromStruct_UnexportedEmbedded(t *testing.T) {
+	ap := NewNamedFromStruct("select :name", testPetTagged{Name: "puppy"})
+	_, actualParams, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testStrategyDefault)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"puppy"}, actualParams)
+	assert.Equal(t, []string{"name"}, ap.Names())
+}
+
+func TestNewNamedFromStruct_NotStruct(t *testing.T) {
+	notStructs := map[string]interface{}{
+		"map":         map[string]interface{}{},
+		"nil pointer": (*testPet)(nil),
+		"nil":         nil,
+	}
+	for caseName, v := range notStructs {
+		ap := NewNamedFromStruct("select 1", v)
+		_, _, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testStrategyDefault)
+		assert.Equal(t, &ErrNotStruct{value: v}, err, caseName)
+
+		assert.Equal(t, &ErrNotStruct{value: v}, SetStruct(NewNamed("select 1"), v), caseName)
+	}
+	assert.Equal(t, "a struct or a non-nil pointer to one is required to set named parameters, got *vparam.testPet", ErrNotStruct{value: (*testPet)(nil)}.Error())
+}
@@ -0,0 +1,357 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vparam
+
+import (
+	"github.com/wojnosystems/vsql/interpolation_strategy"
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenKind identifies what a token found by the tokenizer represents
+type tokenKind int
+
+const (
+	// tokenText is SQL that must be passed through to the database as-is. This includes literals, comments and casts
+	tokenText tokenKind = iota
+	// tokenNamed is a named placeholder such as ":name". The value of the token is the name, without the prefix
+	tokenNamed
+	// tokenPositional is a positional placeholder, the question mark (?)
+	tokenPositional
+)
+
+// placeholderStyle tells the tokenizer which kind of placeholder it should be looking for. Everything else is text
+type placeholderStyle int
+
+const (
+	// placeholderNamed finds :named placeholders, used by Namer
+	placeholderNamed placeholderStyle = iota
+	// placeholderPositional finds ? placeholders, used by Appender
+	placeholderPositional
+)
+
+// syntax is the part of the SQL dialect that changes where literals end
+type syntax struct {
+	// backslashEscapes is true if a backslash escapes the next character of a '' or "" string, as MySQL does by default
+	backslashEscapes bool
+	// escapeStrings is true if E'' strings use backslash escapes, as in Postgres
+	escapeStrings bool
+	// questionOperators is true if ?| and ?& are operators rather than a placeholder followed by an operator, as in Postgres
+	questionOperators bool
+	// dollarQuotes is true if $$ and $tag$ start a string, as in Postgres
+	dollarQuotes bool
+	// hashComments is true if # starts a line comment, as in MySQL
+	hashComments bool
+	// arraySlices is true if a colon inside of [] separates the bounds of an array slice, as in Postgres' arr[1:2]
+	arraySlices bool
+}
+
+// standardSyntax is used when the database is not known: a backslash is just a backslash, as the SQL standard says.
+// Dollar quotes are recognized so that Postgres functions can be used with strategies that do not say what their database is
+var standardSyntax = syntax{dollarQuotes: true}
+
+// syntaxFor gets the syntax of the database the strategy is for
+// @return the syntax of the interpolation_strategy.Dialecter's database, or standardSyntax if the strategy is not a Dialecter or does not name its database
+func syntaxFor(strategy interpolation_strategy.InterpolateStrategy) syntax {
+	d, ok := strategy.(interpolation_strategy.Dialecter)
+	if !ok {
+		return standardSyntax
+	}
+	switch d.Dialect() {
+	case "":
+		return standardSyntax
+	case interpolation_strategy.DialectMySQL, interpolation_strategy.DialectMariaDB:
+		return syntax{backslashEscapes: true, hashComments: true}
+	case interpolation_strategy.DialectPostgres, interpolation_strategy.DialectPostgreSQL:
+		return syntax{escapeStrings: true, questionOperators: true, dollarQuotes: true, arraySlices: true}
+	}
+	return syntax{}
+}
+
+// token is a piece of the SQL query as identified by the tokenizer
+type token struct {
+	kind tokenKind
+	// value is the raw SQL for tokenText, the parameter name for tokenNamed and the placeholder for tokenPositional
+	value string
+	// offset is the byte offset into the original SQL string at which this token started
+	offset int
+}
+
+// tokenize splits the SQL query into text and placeholder tokens.
+//
+// The tokenizer understands enough SQL to avoid treating the contents of literals and comments as placeholders:
+//
+//	'single', "double" and `backtick` quoted strings, including doubled quotes ('it''s')
+//	backslash escapes ('it\'s'), but only in MySQL's '' and "" strings and Postgres' E'' strings
+//	-- line comments and /* block comments */, and for MySQL only, # line comments
+//	Postgres :: casts, which are never named placeholders
+//	Postgres array slices, arr[1:2] and arr[:hi], in which the colon is never a named placeholder. Use arr[(:name)] to index by a named value
+//	Postgres dollar-quoted strings: $$body$$ and $tag$body$tag$, unless the database is known to be another one
+//	for positional placeholders, ?? as an escaped question mark and, for Postgres only, the ?| and ?& JSON operators.
+//	Other databases must escape them as ??| and ??&
+//
+// @param sqlQuery is the query as written by the developer
+// @param style is the type of placeholder to find. The other type is treated as text
+// @param syn is the syntax of the database the query is for
+// @return tokens the pieces of the query, in order. Concatenating the text and placeholders reproduces the query, with escapes removed. Anything malformed is returned as text
+// @return err the first *ErrParse encountered, such as a colon that does not start a name or an unterminated literal. Tokens are still returned for the whole query
+func tokenize(sqlQuery string, style placeholderStyle, syn syntax) (tokens []token, err error) {
+	t := tokenizer{
+		sql:    sqlQuery,
+		style:  style,
+		syntax: syn,
+	}
+	t.run()
+	if t.err != nil {
+		return t.tokens, t.err
+	}
+	return t.tokens, nil
+}
+
+// tokenizer holds the state of a single tokenize call
+type tokenizer struct {
+	sql    string
+	style  placeholderStyle
+	syntax syntax
+	tokens []token
+	// pos is the current byte offset being examined
+	pos int
+	// textStart is the offset of the first byte of text that has not yet been emitted as a token
+	textStart int
+	// err is the first problem found with the query, if any
+	err *ErrParse
+	// nesting is the stack of [ and ( that have not been closed yet, only kept for syntaxes with arraySlices
+	nesting []byte
+}
+
+func (t *tokenizer) run() {
+	for t.pos < len(t.sql) {
+		c := t.sql[t.pos]
+		switch {
+		case c == '\'' || c == '"':
+			t.skipQuoted(c, t.syntax.backslashEscapes)
+		case c == '`':
+			t.skipQuoted(c, false)
+		case (c == 'E' || c == 'e') && t.peek(1) == '\'' && t.syntax.escapeStrings && !t.inIdentifier():
+			t.pos++
+			t.skipQuoted('\'', true)
+		case c == '-' && t.peek(1) == '-', c == '#' && t.syntax.hashComments:
+			t.skipLineComment()
+		case c == '/' && t.peek(1) == '*':
+			t.skipBlockComment()
+		case c == '$' && t.syntax.dollarQuotes && !t.inIdentifier():
+			// a $ inside of a name, such as a$b$c, never starts a dollar quote
+			t.skipDollarQuoted()
+		case c == ':' && t.peek(1) == ':':
+			// Postgres cast, e.g. value::int, is never a placeholder
+			t.pos += 2
+		case (c == '[' || c == '(') && t.syntax.arraySlices:
+			t.nesting = append(t.nesting, c)
+			t.pos++
+		case (c == ']' || c == ')') && t.syntax.arraySlices:
+			if len(t.nesting) != 0 {
+				t.nesting = t.nesting[:len(t.nesting)-1]
+			}
+			t.pos++
+		case c == ':' && t.inArraySubscript():
+			// the colon of an array slice, e.g. arr[1:2], is never a placeholder
+			t.pos++
+		case c == ':' && t.style == placeholderNamed:
+			t.namedPlaceholder()
+		case c == '?' && t.style == placeholderPositional:
+			t.positionalPlaceholder()
+		default:
+			t.pos++
+		}
+	}
+	t.emitText()
+}
+
+// peek returns the byte ahead bytes past the current position, or 0 if that is past the end of the query
+func (t *tokenizer) peek(ahead int) byte {
+	if t.pos+ahead < len(t.sql) {
+		return t.sql[t.pos+ahead]
+	}
+	return 0
+}
+
+// inIdentifier is true if the byte before the current position is part of a name, such as the "e" at the end of "where"
+func (t *tokenizer) inIdentifier() bool {
+	return t.pos > 0 && isIdentifierByte(t.sql[t.pos-1], false)
+}
+
+// inArraySubscript is true if the innermost bracket that is open is the [ of an array subscript, rather than a (
+func (t *tokenizer) inArraySubscript() bool {
+	return len(t.nesting) != 0 && t.nesting[len(t.nesting)-1] == '['
+}
+
+// fail records a parse error at offset. Only the first error is kept as later ones are usually caused by the first
+func (t *tokenizer) fail(offset int, reason string) {
+	if t.err == nil {
+		t.err = newErrParse(t.sql, offset, reason)
+	}
+}
+
+// emitText adds any text not yet emitted as a token
+func (t *tokenizer) emitText() {
+	if t.textStart < t.pos {
+		t.tokens = append(t.tokens, token{kind: tokenText, value: t.sql[t.textStart:t.pos], offset: t.textStart})
+	}
+	t.textStart = t.pos
+}
+
+// skipQuoted moves past a quoted string or identifier. Quotes are escaped by doubling them, or, if backslash is true, with a backslash
+// An unterminated quote consumes the remainder of the query
+func (t *tokenizer) skipQuoted(quote byte, backslash bool) {
+	start := t.pos
+	t.pos++
+	for t.pos < len(t.sql) {
+		c := t.sql[t.pos]
+		switch {
+		case c == '\\' && backslash:
+			t.pos += 2
+		case c == quote && t.peek(1) == quote:
+			t.pos += 2
+		case c == quote:
+			t.pos++
+			return
+		default:
+			t.pos++
+		}
+	}
+	t.pos = len(t.sql)
+	t.fail(start, "unterminated quoted string")
+}
+
+// skipLineComment moves past a -- or # comment, up to and including the newline
+func (t *tokenizer) skipLineComment() {
+	end := strings.IndexByte(t.sql[t.pos:], '\n')
+	if end < 0 {
+		t.pos = len(t.sql)
+		return
+	}
+	t.pos += end + 1
+}
+
+// skipBlockComment moves past a /* comment */
+func (t *tokenizer) skipBlockComment() {
+	end := strings.Index(t.sql[t.pos+2:], "*/")
+	if end < 0 {
+		t.fail(t.pos, "unterminated block comment")
+		t.pos = len(t.sql)
+		return
+	}
+	t.pos += 2 + end + 2
+}
+
+// skipDollarQuoted moves past a Postgres dollar-quoted string. If the dollar sign does not start a dollar-quote, such as $1, only the dollar sign is consumed
+func (t *tokenizer) skipDollarQuoted() {
+	tagEnd := t.pos + 1
+	for tagEnd < len(t.sql) && isIdentifierByte(t.sql[tagEnd], tagEnd == t.pos+1) {
+		tagEnd++
+	}
+	if tagEnd >= len(t.sql) || t.sql[tagEnd] != '$' {
+		t.pos++
+		return
+	}
+	tag := t.sql[t.pos : tagEnd+1]
+	end := strings.Index(t.sql[tagEnd+1:], tag)
+	if end < 0 {
+		t.fail(t.pos, "unterminated dollar-quoted string")
+		t.pos = len(t.sql)
+		return
+	}
+	t.pos = tagEnd + 1 + end + len(tag)
+}
+
+// positionalPlaceholder emits the ? placeholder at the current position, unless it is:
+//
+//	?? which is an escaped question mark, and is emitted as a single ? of text
+//	?| or ?& which are Postgres JSON operators, and are left as text when the syntax has them
+func (t *tokenizer) positionalPlaceholder() {
+	switch {
+	case t.peek(1) == '?':
+		t.emitText()
+		t.tokens = append(t.tokens, token{kind: tokenText, value: AppenderPlaceholder, offset: t.pos})
+		t.pos += 2
+		t.textStart = t.pos
+		return
+	case t.syntax.questionOperators && (t.peek(1) == '&' || t.peek(1) == '|' && t.peek(2) != '|'):
+		// ?|| is a placeholder followed by the concatenation operator
+		t.pos += 2
+		return
+	}
+	t.emitText()
+	t.tokens = append(t.tokens, token{kind: tokenPositional, value: AppenderPlaceholder, offset: t.pos})
+	t.pos++
+	t.textStart = t.pos
+}
+
+// namedPlaceholder emits the :name placeholder at the current position.
+// A colon not followed by a name is an error, except for MySQL's := assignment operator. The colon is left as text
+func (t *tokenizer) namedPlaceholder() {
+	nameEnd := t.pos + 1
+	for nameEnd < len(t.sql) && isIdentifierByte(t.sql[nameEnd], nameEnd == t.pos+1) {
+		nameEnd++
+	}
+	if nameEnd == t.pos+1 {
+		if t.peek(1) != '=' {
+			t.fail(t.pos, "named placeholder must be followed by a name starting with a-zA-Z_")
+		}
+		t.pos++
+		return
+	}
+	t.emitText()
+	t.tokens = append(t.tokens, token{kind: tokenNamed, value: t.sql[t.pos+1 : nameEnd], offset: t.pos})
+	t.pos = nameEnd
+	t.textStart = t.pos
+}
+
+// isIdentifierByte is true if c may appear in a placeholder name or dollar-quote tag: a-zA-Z0-9_, but not a number if first
+func isIdentifierByte(c byte, first bool) bool {
+	return c == '_' ||
+		('a' <= c && c <= 'z') ||
+		('A' <= c && c <= 'Z') ||
+		(!first && '0' <= c && c <= '9')
+}
+
+// snippetRadius is how many bytes on either side of the problem are included in ErrParse.Snippet
+const snippetRadius = 20
+
+func newErrParse(sqlQuery string, offset int, reason string) *ErrParse {
+	line, column := lineColumn(sqlQuery, offset)
+	snippetStart := offset - snippetRadius
+	if snippetStart < 0 {
+		snippetStart = 0
+	}
+	snippetEnd := offset + snippetRadius
+	if snippetEnd > len(sqlQuery) {
+		snippetEnd = len(sqlQuery)
+	}
+	return &ErrParse{
+		Reason:  reason,
+		Offset:  offset,
+		Line:    line,
+		Column:  column,
+		Snippet: strings.ToValidUTF8(sqlQuery[snippetStart:snippetEnd], ""),
+	}
+}
+
+// lineColumn converts a byte offset into the query into a 1-based line and 1-based column, counted in characters
+func lineColumn(sqlQuery string, offset int) (line, column int) {
+	lineStart := strings.LastIndexByte(sqlQuery[:offset], '\n') + 1
+	return strings.Count(sqlQuery[:offset], "\n") + 1, utf8.RuneCountInString(sqlQuery[lineStart:offset]) + 1
+}
@@ -0,0 +1,326 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vparam
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wojnosystems/vsql/interpolation_strategy"
+	"testing"
+)
+
+func TestTokenize_Names(t *testing.T) {
+	cases := map[string]struct {
+		queryIn       string
+		namesExpected []string
+	}{
+		"none": {
+			queryIn:       "select * from my_table",
+			namesExpected: []string{},
+		},
+		"in order": {
+			queryIn:       "select * from my_table where a = :pet and b = :age and c = :pet",
+			namesExpected: []string{"pet", "age", "pet"},
+		},
+		"single quote with doubled quotes": {
+			queryIn:       `select 'it''s :nope', :pet`,
+			namesExpected: []string{"pet"},
+		},
+		"double quote and backtick": {
+			queryIn:       "select \"a:nope\", `b:nope`, :pet",
+			namesExpected: []string{"pet"},
+		},
+		"line comment ends at newline": {
+			queryIn:       "select :pet -- :nope\n, :age",
+			namesExpected: []string{"pet", "age"},
+		},
+		"block comment": {
+			queryIn:       "select /* :nope\n:nope */ :pet",
+			namesExpected: []string{"pet"},
+		},
+		"casts": {
+			queryIn:       "select :pet::text, '1'::int, :age",
+			namesExpected: []string{"pet", "age"},
+		},
+		"dollar quoted": {
+			queryIn:       "select $$ :nope $$, $body$ :nope $$ :nope $body$, :pet",
+			namesExpected: []string{"pet"},
+		},
+		"ordinal is not a dollar quote": {
+			queryIn:       "select $1, :pet, $2",
+			namesExpected: []string{"pet"},
+		},
+		"unterminated literal": {
+			queryIn:       "select :pet, ':nope",
+			namesExpected: []string{"pet"},
+		},
+		"lone colon": {
+			queryIn:       "select :pet, a[1 : 2]",
+			namesExpected: []string{"pet"},
+		},
+	}
+	for caseName, c := range cases {
+		tokens, _ := tokenize(c.queryIn, placeholderNamed, standardSyntax)
+		actual := make([]string, 0, len(tokens))
+		for _, tok := range tokens {
+			if tok.kind == tokenNamed {
+				actual = append(actual, tok.value)
+			}
+		}
+		assert.Equal(t, c.namesExpected, actual, caseName)
+	}
+}
+
+func TestTokenize_RoundTrip(t *testing.T) {
+	queries := []string{
+		"select * from my_table",
+		"select :pet::text, 'it''s :nope' -- :nope\n/* ? */ from t where a = ? and b = $tag$ ? $tag$",
+		"select '",
+		"/*",
+	}
+	for _, style := range []placeholderStyle{placeholderNamed, placeholderPositional} {
+		for _, q := range queries {
+			rebuilt := ""
+			tokens, _ := tokenize(q, style, standardSyntax)
+			for _, tok := range tokens {
+				if tok.kind == tokenNamed {
+					rebuilt += NamedPlaceholderPrefix
+				}
+				rebuilt += tok.value
+				assert.Equal(t, rebuilt, q[:len(rebuilt)])
+				assert.True(t, tok.offset < len(rebuilt))
+			}
+			assert.Equal(t, q, rebuilt)
+		}
+	}
+}
+
+func TestTokenize_Positional(t *testing.T) {
+	tokens, err := tokenize("select ':x?', ? from t where a = :b", placeholderPositional, standardSyntax)
+	assert.NoError(t, err)
+	assert.Equal(t, []token{
+		{kind: tokenText, value: "select ':x?', ", offset: 0},
+		{kind: tokenPositional, value: "?", offset: 14},
+		{kind: tokenText, value: " from t where a = :b", offset: 15},
+	}, tokens)
+}
+
+func TestTokenize_ParseErrors(t *testing.T) {
+	cases := map[string]struct {
+		queryIn  string
+		style    placeholderStyle
+		expected *ErrParse
+	}{
+		"colon without name": {
+			queryIn: "select *\nfrom t where a = : and b = :b",
+			style:   placeholderNamed,
+			expected: &ErrParse{
+				Reason:  "named placeholder must be followed by a name starting with a-zA-Z_",
+				Offset:  26,
+				Line:    2,
+				Column:  18,
+				Snippet: " *\nfrom t where a = : and b = :b",
+			},
+		},
+		"colon at end": {
+			queryIn: "select :",
+			style:   placeholderNamed,
+			expected: &ErrParse{
+				Reason:  "named placeholder must be followed by a name starting with a-zA-Z_",
+				Offset:  7,
+				Line:    1,
+				Column:  8,
+				Snippet: "select :",
+			},
+		},
+		"name starting with a number": {
+			queryIn: "select :1abc",
+			style:   placeholderNamed,
+			expected: &ErrParse{
+				Reason:  "named placeholder must be followed by a name starting with a-zA-Z_",
+				Offset:  7,
+				Line:    1,
+				Column:  8,
+				Snippet: "select :1abc",
+			},
+		},
+		"unterminated quote": {
+			queryIn: "select 'é', ? from t where a = 'oops",
+			style:   placeholderPositional,
+			expected: &ErrParse{
+				Reason:  "unterminated quoted string",
+				Offset:  32,
+				Line:    1,
+				Column:  32,
+				Snippet: " ? from t where a = 'oops",
+			},
+		},
+		"unterminated comment": {
+			queryIn: "select /* oops",
+			style:   placeholderNamed,
+			expected: &ErrParse{
+				Reason:  "unterminated block comment",
+				Offset:  7,
+				Line:    1,
+				Column:  8,
+				Snippet: "select /* oops",
+			},
+		},
+		"unterminated dollar quote": {
+			queryIn: "select $a$ oops $b$",
+			style:   placeholderNamed,
+			expected: &ErrParse{
+				Reason:  "unterminated dollar-quoted string",
+				Offset:  7,
+				Line:    1,
+				Column:  8,
+				Snippet: "select $a$ oops $b$",
+			},
+		},
+	}
+	for caseName, c := range cases {
+		_, err := tokenize(c.queryIn, c.style, standardSyntax)
+		assert.Equal(t, c.expected, err, caseName)
+	}
+}
+
+func TestTokenize_MySQLAssignment(t *testing.T) {
+	tokens, err := tokenize("select @total := @total + :amount", placeholderNamed, standardSyntax)
+	assert.NoError(t, err)
+	assert.Equal(t, []token{
+		{kind: tokenText, value: "select @total := @total + ", offset: 0},
+		{kind: tokenNamed, value: "amount", offset: 26},
+	}, tokens)
+}
+
+func TestTokenize_Backslashes(t *testing.T) {
+	mysql := syntax{backslashEscapes: true}
+	postgres := syntax{escapeStrings: true}
+	cases := map[string]struct {
+		queryIn       string
+		syntax        syntax
+		namesExpected []string
+	}{
+		"standard backslash is not an escape": {
+			queryIn:       `select 'C:\', :pet`,
+			syntax:        standardSyntax,
+			namesExpected: []string{"pet"},
+		},
+		"postgres backslash is not an escape": {
+			queryIn:       `select 'C:\', :pet`,
+			syntax:        postgres,
+			namesExpected: []string{"pet"},
+		},
+		"postgres escape string": {
+			queryIn:       `select E'it\'s :nope', e'\\', :pet`,
+			syntax:        postgres,
+			namesExpected: []string{"pet"},
+		},
+		"postgres type name ending in e": {
+			queryIn:       `select name'C:\', :pet`,
+			syntax:        postgres,
+			namesExpected: []string{"pet"},
+		},
+		"postgres identifier is never escaped": {
+			queryIn:       `select "C:\", :pet`,
+			syntax:        postgres,
+			namesExpected: []string{"pet"},
+		},
+		"mysql escaped quotes": {
+			queryIn:       `select 'it\'s :nope', "a\":nope", :pet`,
+			syntax:        mysql,
+			namesExpected: []string{"pet"},
+		},
+		"mysql backtick is never escaped": {
+			queryIn:       "select `C:\\`, :pet",
+			syntax:        mysql,
+			namesExpected: []string{"pet"},
+		},
+	}
+	for caseName, c := range cases {
+		tokens, err := tokenize(c.queryIn, placeholderNamed, c.syntax)
+		assert.NoError(t, err, caseName)
+		actual := make([]string, 0, len(c.namesExpected))
+		for _, tok := range tokens {
+			if tok.kind == tokenNamed {
+				actual = append(actual, tok.value)
+			}
+		}
+		assert.Equal(t, c.namesExpected, actual, caseName)
+	}
+}
+
+func TestTokenize_Dialects(t *testing.T) {
+	mysql := syntaxFor(interpolation_strategy.NewQuestionMark())
+	postgres := syntaxFor(interpolation_strategy.NewDollarOrdinal())
+	sqlServer := syntaxFor(interpolation_strategy.NewAtPOrdinal())
+	cases := map[string]struct {
+		queryIn       string
+		syntax        syntax
+		namesExpected []string
+	}{
+		"mysql dollar signs in names": {
+			queryIn:       "select a$b$c from t where id = :id",
+			syntax:        mysql,
+			namesExpected: []string{"id"},
+		},
+		"mysql hash comment": {
+			queryIn:       "select 1 # what:ever\n, :id",
+			syntax:        mysql,
+			namesExpected: []string{"id"},
+		},
+		"postgres dollar quote": {
+			queryIn:       "select $fn$ :nope $fn$, :id",
+			syntax:        postgres,
+			namesExpected: []string{"id"},
+		},
+		"postgres dollar signs in names": {
+			queryIn:       "select a$b$c from t where id = :id",
+			syntax:        postgres,
+			namesExpected: []string{"id"},
+		},
+		"postgres array slice": {
+			queryIn:       "select arr[1:2], arr[:hi], arr[lo:] from t where id = :id",
+			syntax:        postgres,
+			namesExpected: []string{"id"},
+		},
+		"postgres array slice of names": {
+			queryIn:       "select arr[:lo:hi], arr[(:i)], arr[(:lo):(:hi)] from t",
+			syntax:        postgres,
+			namesExpected: []string{"i", "lo", "hi"},
+		},
+		"postgres hash is an operator": {
+			queryIn:       "select 1 # :id",
+			syntax:        postgres,
+			namesExpected: []string{"id"},
+		},
+		"sql server has no dollar quotes": {
+			queryIn:       "select $$, :id, $$",
+			syntax:        sqlServer,
+			namesExpected: []string{"id"},
+		},
+	}
+	for caseName, c := range cases {
+		tokens, err := tokenize(c.queryIn, placeholderNamed, c.syntax)
+		assert.NoError(t, err, caseName)
+		actual := make([]string, 0, len(c.namesExpected))
+		for _, tok := range tokens {
+			if tok.kind == tokenNamed {
+				actual = append(actual, tok.value)
+			}
+		}
+		assert.Equal(t, c.namesExpected, actual, caseName)
+	}
+}
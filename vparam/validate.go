@@ -0,0 +1,101 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vparam
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Validate checks the named placeholders in the query of n against the values that have been Set, reporting every problem at once
+// Use this in tests, or before running a query, to catch typos like ":usr_id" vs "user_id"
+// The database is not known, so literals are found using the standard SQL syntax, in which a backslash does not escape a quote. Use SetStrict to check with the syntax of the strategy instead
+// @param n is the named query to check
+// @return err nil if every placeholder has a value and every value has a placeholder, *ErrParse if the query cannot be parsed, otherwise *ErrNamedParams
+func Validate(n Namer) error {
+	return validateNamed(n.SQLQueryUnInterpolated(), n.Names(), standardSyntax)
+}
+
+// validateNamed compares the placeholders in sqlQuery with the names of the values that were provided
+func validateNamed(sqlQuery string, names []string, syn syntax) error {
+	tokens, err := tokenize(sqlQuery, placeholderNamed, syn)
+	if err != nil {
+		return err
+	}
+	provided := make(map[string]bool, len(names))
+	for _, name := range names {
+		provided[name] = false
+	}
+	result := &ErrNamedParams{}
+	for _, tok := range tokens {
+		if tok.kind != tokenNamed {
+			continue
+		}
+		used, ok := provided[tok.value]
+		if !ok {
+			result.Missing = append(result.Missing, tok.value)
+			// only report each missing name once
+			provided[tok.value] = true
+			continue
+		}
+		if !used {
+			provided[tok.value] = true
+		}
+	}
+	for name, used := range provided {
+		if !used {
+			result.Unused = append(result.Unused, name)
+		}
+	}
+	if len(result.Missing) == 0 && len(result.Unused) == 0 {
+		return nil
+	}
+	sort.Strings(result.Unused)
+	return result
+}
+
+// ErrNamedParams reports all of the mismatches between the named placeholders in a query and the values provided for it
+type ErrNamedParams struct {
+	// Missing are the names of placeholders without a value, in the order they first appear in the query
+	Missing []string
+	// Unused are the names of values that no placeholder refers to, sorted
+	Unused []string
+}
+
+// Error satisfies the Error interface and lists every missing and unused name
+func (e ErrNamedParams) Error() string {
+	parts := make([]string, 0, 2)
+	if len(e.Missing) != 0 {
+		parts = append(parts, fmt.Sprintf(`missing values for named parameters: "%s"`, strings.Join(e.Missing, `", "`)))
+	}
+	if len(e.Unused) != 0 {
+		parts = append(parts, fmt.Sprintf(`values not used by the query: "%s"`, strings.Join(e.Unused, `", "`)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Unwrap returns an *ErrMissingNamedParam for each missing name and an *ErrUnusedNamedParam for each unused name, so errors.As can find them
+func (e ErrNamedParams) Unwrap() []error {
+	errs := make([]error, 0, len(e.Missing)+len(e.Unused))
+	for _, name := range e.Missing {
+		errs = append(errs, &ErrMissingNamedParam{name: name})
+	}
+	for _, name := range e.Unused {
+		errs = append(errs, &ErrUnusedNamedParam{name: name})
+	}
+	return errs
+}
@@ -0,0 +1,94 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vparam
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/wojnosystems/vsql/interpolation_strategy"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cases := map[string]struct {
+		queryIn      string
+		parametersIn map[string]interface{}
+		expected     error
+	}{
+		"valid": {
+			queryIn:      "select * from my_table where value1 = :pet and value2 = :age and value3 = :pet",
+			parametersIn: map[string]interface{}{"age": 5, "pet": "puppy"},
+		},
+		"missing and unused": {
+			queryIn:      "select * from my_table where value1 = :usr_id and value2 = :age and value3 = :usr_id and value4 = :color",
+			parametersIn: map[string]interface{}{"user_id": 5, "pet": "puppy", "age": 1},
+			expected: &ErrNamedParams{
+				Missing: []string{"usr_id", "color"},
+				Unused:  []string{"pet", "user_id"},
+			},
+		},
+		"parse error": {
+			queryIn:      "select :",
+			parametersIn: map[string]interface{}{},
+			expected: &ErrParse{
+				Reason:  "named placeholder must be followed by a name starting with a-zA-Z_",
+				Offset:  7,
+				Line:    1,
+				Column:  8,
+				Snippet: "select :",
+			},
+		},
+	}
+	for caseName, c := range cases {
+		err := Validate(NewNamedWithData(c.queryIn, c.parametersIn))
+		assert.Equal(t, c.expected, err, caseName)
+	}
+}
+
+func TestErrNamedParams(t *testing.T) {
+	err := error(&ErrNamedParams{
+		Missing: []string{"usr_id"},
+		Unused:  []string{"user_id"},
+	})
+	assert.Equal(t, `missing values for named parameters: "usr_id"; values not used by the query: "user_id"`, err.Error())
+
+	var missing *ErrMissingNamedParam
+	assert.True(t, errors.As(err, &missing))
+	assert.Equal(t, "usr_id", missing.name)
+
+	var unused *ErrUnusedNamedParam
+	assert.True(t, errors.As(err, &unused))
+	assert.Equal(t, "user_id", unused.name)
+}
+
+func TestNamedParameter_InterpolateStrict(t *testing.T) {
+	ap := NewNamedWithData("select * from my_table where value1 = :pet", map[string]interface{}{"pet": "puppy", "age": 5})
+	_, _, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), &testStrategyDefault)
+	assert.NoError(t, err, "unused values are ignored unless strict")
+
+	ap.SetStrict(true)
+	_, _, err = ap.Interpolate(ap.SQLQueryUnInterpolated(), &testStrategyDefault)
+	assert.Equal(t, &ErrNamedParams{Unused: []string{"age"}}, err)
+}
+
+func TestNamedParameter_InterpolateStrictDialect(t *testing.T) {
+	ap := NewNamedWithData(`select 'it\'s :nope', :pet`, map[string]interface{}{"pet": "puppy"})
+	ap.SetStrict(true)
+	interpolated, params, err := ap.Interpolate(ap.SQLQueryUnInterpolated(), interpolation_strategy.NewQuestionMark())
+	assert.NoError(t, err, "literals are checked using the syntax of the strategy")
+	assert.Equal(t, `select 'it\'s :nope', ?`, interpolated)
+	assert.Equal(t, []interface{}{"puppy"}, params)
+}
@@ -0,0 +1,167 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vrow
+
+import (
+	"context"
+	"fmt"
+	"github.com/wojnosystems/vsql"
+	"github.com/wojnosystems/vsql/vparam"
+	"github.com/wojnosystems/vsql/vquery"
+	"github.com/wojnosystems/vsql/vrows"
+	"reflect"
+	"time"
+)
+
+// ScanFunc reads a vrow into dest, which is a pointer to a new, zero-valued element of the slice being collected
+type ScanFunc func(ro vrows.Rower, dest interface{}) (err error)
+
+// NoMaxRows tells AllMax and QueryAllMax to collect every vrow
+const NoMaxRows = 0
+
+// All collects every vrow into the slice pointed to by dest, replacing its contents
+//
+// All will also handle cleaning up the Rowser record when it returns, preventing memory leaks
+//
+// @param r comes from Query() calls
+// @param dest is a pointer to a slice. Each vrow is scanned into a new element of that slice
+// @param scan reads each vrow into a pointer to the new element. If nil, maps such as vsql.H are read with ScanMap, structs with ScanStruct, and anything else, including sql.Scanners like sql.NullString and time.Time, is read as the only column with Scan
+// @return err the database error encountered, or that was returned from scan. The vrows read before the error are kept in dest
+//
+// Like Each, no vrows is not an error: dest is set to an empty slice
+//
+// Example:
+//   var users []User
+//   err := vrow.QueryAll(db, ctx, vparam.New("select name, age from users"), &users, nil)
+//
+//   var names []string
+//   err := vrow.QueryAll(db, ctx, vparam.New("select name from users"), &names, nil)
+func All(r vrows.Rowser, dest interface{}, scan ScanFunc) (err error) {
+	return AllMax(r, NoMaxRows, dest, scan)
+}
+
+// AllMax is All, but fails with an *ErrTooManyRows if there are more than maxRows vrows, protecting against unbounded queries using up all of the memory
+// @param maxRows is the most vrows to collect. NoMaxRows means there is no limit
+// @return err is an *ErrTooManyRows if there were more than maxRows. dest holds the first maxRows vrows
+func AllMax(r vrows.Rowser, maxRows int, dest interface{}, scan ScanFunc) (err error) {
+	c, err := newCollector(dest, maxRows, scan)
+	if err != nil {
+		_ = r.Close()
+		return err
+	}
+	defer c.finish()
+	return Each(r, c.eachRow)
+}
+
+// QueryAll runs the query and collects every vrow into the slice pointed to by dest. See All
+func QueryAll(queryer vquery.Queryer, ctx context.Context, q vparam.Queryer, dest interface{}, scan ScanFunc) (err error) {
+	return QueryAllMax(queryer, ctx, q, NoMaxRows, dest, scan)
+}
+
+// QueryAllMax runs the query and collects at most maxRows vrows into the slice pointed to by dest. See AllMax
+func QueryAllMax(queryer vquery.Queryer, ctx context.Context, q vparam.Queryer, maxRows int, dest interface{}, scan ScanFunc) (err error) {
+	c, err := newCollector(dest, maxRows, scan)
+	if err != nil {
+		return err
+	}
+	defer c.finish()
+	return QueryEach(queryer, ctx, q, c.eachRow)
+}
+
+// collector appends each vrow to a slice
+type collector struct {
+	// slice is the value dest points to
+	slice reflect.Value
+	// rows is built up and assigned to slice when done
+	rows    reflect.Value
+	maxRows int
+	scan    ScanFunc
+}
+
+func newCollector(dest interface{}, maxRows int, scan ScanFunc) (c *collector, err error) {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("vrow: All requires a non-nil pointer to a slice, got %T", dest)
+	}
+	c = &collector{
+		slice:   rv.Elem(),
+		maxRows: maxRows,
+		scan:    scan,
+	}
+	c.rows = reflect.MakeSlice(c.slice.Type(), 0, 0)
+	if c.scan == nil {
+		c.scan = defaultScanFunc(c.slice.Type().Elem())
+	}
+	return c, nil
+}
+
+var (
+	hType    = reflect.TypeOf(vsql.H{})
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// defaultScanFunc is how elements of type t are read when no ScanFunc is given
+func defaultScanFunc(t reflect.Type) ScanFunc {
+	if t.Kind() == reflect.Map && hType.ConvertibleTo(t) {
+		return func(ro vrows.Rower, dest interface{}) error {
+			m, err := ScanMap(ro)
+			if err != nil {
+				return err
+			}
+			reflect.ValueOf(dest).Elem().Set(reflect.ValueOf(m).Convert(t))
+			return nil
+		}
+	}
+	// structs that are values of a single column, such as sql.NullString, are scanned by the driver
+	if t.Kind() == reflect.Struct && t != timeType && !reflect.PtrTo(t).Implements(scannerType) {
+		return ScanStruct
+	}
+	return func(ro vrows.Rower, dest interface{}) error {
+		return ro.Scan(dest)
+	}
+}
+
+func (c *collector) eachRow(ro vrows.Rower) (stop bool, err error) {
+	if c.maxRows != NoMaxRows && c.rows.Len() >= c.maxRows {
+		return true, &ErrTooManyRows{max: c.maxRows}
+	}
+	item := reflect.New(c.slice.Type().Elem())
+	if err = c.scan(ro, item.Interface()); err != nil {
+		return true, err
+	}
+	c.rows = reflect.Append(c.rows, item.Elem())
+	return false, nil
+}
+
+// finish puts the collected vrows into dest
+func (c *collector) finish() {
+	c.slice.Set(c.rows)
+}
+
+// ErrTooManyRows is returned by AllMax and QueryAllMax when the query returned more vrows than allowed
+type ErrTooManyRows struct {
+	max int
+}
+
+// Max is the most vrows that were allowed
+func (e ErrTooManyRows) Max() int {
+	return e.max
+}
+
+// Error satisfies the Error interface and says how many vrows were allowed
+func (e ErrTooManyRows) Error() string {
+	return fmt.Sprintf("query returned more than the maximum of %d rows", e.max)
+}
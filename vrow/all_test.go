@@ -0,0 +1,156 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vrow
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/wojnosystems/vsql/vquery"
+	"github.com/wojnosystems/vsql/vrows"
+	"testing"
+	"time"
+)
+
+func TestAll(t *testing.T) {
+	cases := map[string]struct {
+		rows     []vrows.Rower
+		maxRows  int
+		expected []string
+		err      error
+	}{
+		"none": {
+			expected: []string{},
+		},
+		"all": {
+			rows: []vrows.Rower{
+				vrows.NewRowerMock([]string{"name"}, "bob"),
+				vrows.NewRowerMock([]string{"name"}, "alice"),
+			},
+			expected: []string{"bob", "alice"},
+		},
+		"at max": {
+			rows: []vrows.Rower{
+				vrows.NewRowerMock([]string{"name"}, "bob"),
+				vrows.NewRowerMock([]string{"name"}, "alice"),
+			},
+			maxRows:  2,
+			expected: []string{"bob", "alice"},
+		},
+		"over max": {
+			rows: []vrows.Rower{
+				vrows.NewRowerMock([]string{"name"}, "bob"),
+				vrows.NewRowerMock([]string{"name"}, "alice"),
+			},
+			maxRows:  1,
+			expected: []string{"bob"},
+			err:      &ErrTooManyRows{max: 1},
+		},
+	}
+
+	for caseName, c := range cases {
+		rowsMock := vrows.NewRowserMock(c.rows...)
+		names := []string{"previous"}
+		err := AllMax(rowsMock, c.maxRows, &names, nil)
+		assert.Equal(t, c.err, err, caseName)
+		assert.Equal(t, c.expected, names, caseName)
+		rowsMock.AssertCalled(t, "Close")
+	}
+}
+
+func TestAll_Structs(t *testing.T) {
+	rowsMock := vrows.NewRowserMock(
+		vrows.NewRowerMock([]string{"id", "name"}, 1, "bob"),
+		vrows.NewRowerMock([]string{"id", "name"}, 2, "alice"),
+	)
+	var users []testUser
+	err := All(rowsMock, &users, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []testUser{{ID: 1, Name: "bob"}, {ID: 2, Name: "alice"}}, users)
+}
+
+func TestAll_SingleColumnStructs(t *testing.T) {
+	rowsMock := vrows.NewRowserMock(
+		vrows.NewRowerMock([]string{"nickname"}, "bob"),
+		vrows.NewRowerMock([]string{"nickname"}, nil),
+	)
+	var nicknames []sql.NullString
+	err := All(rowsMock, &nicknames, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []sql.NullString{{String: "bob", Valid: true}, {}}, nicknames)
+
+	created := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	rowsMock = vrows.NewRowserMock(vrows.NewRowerMock([]string{"created"}, created))
+	var times []time.Time
+	err = All(rowsMock, &times, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{created}, times)
+}
+
+func TestAll_UnnamedMaps(t *testing.T) {
+	rowsMock := vrows.NewRowserMock(
+		newMapRowMock([]string{"id", "name"}, []string{"BIGINT", "TEXT"}, int64(1), []byte("bob")),
+	)
+	var rows []map[string]interface{}
+	err := All(rowsMock, &rows, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"id": int64(1), "name": "bob"}}, rows)
+}
+
+func TestAll_ScanFunc(t *testing.T) {
+	errExpected := errors.New("bad value")
+	rowsMock := vrows.NewRowserMock(
+		vrows.NewRowerMock([]string{"name"}, "bob"),
+		vrows.NewRowerMock([]string{"name"}, "alice"),
+	)
+	var names []string
+	err := All(rowsMock, &names, func(ro vrows.Rower, dest interface{}) error {
+		var name string
+		if err := ro.Scan(&name); err != nil {
+			return err
+		}
+		if name == "alice" {
+			return errExpected
+		}
+		*dest.(*string) = name + "!"
+		return nil
+	})
+	assert.Equal(t, errExpected, err)
+	assert.Equal(t, []string{"bob!"}, names)
+}
+
+func TestAll_NotSlicePointer(t *testing.T) {
+	rowsMock := &vrows.RowserMock{}
+	rowsMock.On("Close").
+		Once().
+		Return(nil)
+	var names []string
+	assert.Error(t, All(rowsMock, names, nil))
+	rowsMock.AssertExpectations(t)
+}
+
+func TestQueryAll_NoRows(t *testing.T) {
+	qqMock := &vquery.QueryerMock{}
+	qqMock.On("Query", context.Background(), nil).
+		Once().
+		Return(nil, sql.ErrNoRows)
+	var names []string
+	err := QueryAll(qqMock, context.Background(), nil, &names, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, names)
+	qqMock.AssertExpectations(t)
+}
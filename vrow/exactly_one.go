@@ -0,0 +1,97 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vrow
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wojnosystems/vsql/vparam"
+	"github.com/wojnosystems/vsql/vquery"
+	"github.com/wojnosystems/vsql/vrows"
+)
+
+// ExactlyOne is a stricter One, for lookups that must find a single vrow, such as by a unique key
+//
+// ExactlyOne will also handle cleaning up the Rowser record when it returns, preventing memory leaks
+//
+// @param r comes from Query() calls
+// @param theRow is a predicate to call for the vrow. If you return an error, that error will be passed to the caller of ExactlyOne
+// @return err *ErrRowNotFound if there were no vrows, *ErrMultipleRows if there was more than one, the database error encountered, or that was returned from theRow
+//
+// theRow is called before it is known whether there are more vrows, so whatever it read should be discarded if ExactlyOne returns an error
+//
+// Example:
+//   u := User{}
+//   err := vrow.QueryExactlyOne(db, ctx, vparam.NewAppendWithData("select * from users where email = ?", email), func(ro vrows.Rower) error {
+//     return vrow.ScanStruct(ro, &u)
+//   })
+//   if _, ok := err.(*vrow.ErrRowNotFound); ok { ... }
+func ExactlyOne(r vrows.Rowser, theRow func(ro vrows.Rower) (err error)) (err error) {
+	defer func() { _ = r.Close() }()
+	ro := r.Next()
+	if ro == nil {
+		if err = r.Err(); err != nil {
+			return err
+		}
+		return &ErrRowNotFound{}
+	}
+	if err = theRow(ro); err != nil {
+		return err
+	}
+	if r.Next() != nil {
+		return &ErrMultipleRows{}
+	}
+	return r.Err()
+}
+
+// QueryExactlyOne runs the query and reads its only vrow. See ExactlyOne
+func QueryExactlyOne(queryer vquery.Queryer, ctx context.Context, q vparam.Queryer, theRow func(ro vrows.Rower) (err error)) (err error) {
+	var qr vrows.Rowser
+	qr, err = queryer.Query(ctx, q)
+	if err == sql.ErrNoRows {
+		if qr != nil {
+			_ = qr.Close()
+		}
+		return &ErrRowNotFound{}
+	}
+	if err != nil {
+		return
+	}
+	return ExactlyOne(qr, theRow)
+}
+
+// ErrRowNotFound is returned by ExactlyOne when the query returned no vrows. It matches sql.ErrNoRows with errors.Is
+type ErrRowNotFound struct {
+}
+
+// Error satisfies the Error interface
+func (e ErrRowNotFound) Error() string {
+	return "query returned no rows, expected exactly one"
+}
+
+// Is allows errors.Is(err, sql.ErrNoRows) to keep working for callers that already check for it
+func (e ErrRowNotFound) Is(target error) bool {
+	return target == sql.ErrNoRows
+}
+
+// ErrMultipleRows is returned by ExactlyOne when the query returned more than one vrow
+type ErrMultipleRows struct {
+}
+
+// Error satisfies the Error interface
+func (e ErrMultipleRows) Error() string {
+	return "query returned more than one row, expected exactly one"
+}
@@ -0,0 +1,101 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vrow
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/wojnosystems/vsql/vquery"
+	"github.com/wojnosystems/vsql/vrows"
+	"testing"
+)
+
+func TestExactlyOne(t *testing.T) {
+	errIterating := errors.New("connection lost")
+	cases := map[string]struct {
+		rows     []vrows.Rower
+		iterErr  error
+		expected error
+		called   int
+	}{
+		"none": {
+			expected: &ErrRowNotFound{},
+		},
+		"one": {
+			rows:   []vrows.Rower{&vrows.RowerMock{}},
+			called: 1,
+		},
+		"two": {
+			rows:     []vrows.Rower{&vrows.RowerMock{}, &vrows.RowerMock{}},
+			expected: &ErrMultipleRows{},
+			called:   1,
+		},
+		"error before first": {
+			iterErr:  errIterating,
+			expected: errIterating,
+		},
+		"error after first": {
+			rows:     []vrows.Rower{&vrows.RowerMock{}},
+			iterErr:  errIterating,
+			expected: errIterating,
+			called:   1,
+		},
+	}
+
+	for caseName, c := range cases {
+		rowsMock := &vrows.RowserMock{}
+		for _, row := range c.rows {
+			rowsMock.On("Next").
+				Once().
+				Return(row)
+		}
+		rowsMock.On("Next").
+			Return(nil)
+		rowsMock.On("Err").
+			Return(c.iterErr)
+		rowsMock.On("Close").
+			Once().
+			Return(nil)
+		called := 0
+
+		err := ExactlyOne(rowsMock, func(ro vrows.Rower) error {
+			called++
+			return nil
+		})
+
+		assert.Equal(t, c.expected, err, caseName)
+		assert.Equal(t, c.called, called, caseName)
+		rowsMock.AssertCalled(t, "Close")
+	}
+}
+
+func TestQueryExactlyOne_NoRows(t *testing.T) {
+	qqMock := &vquery.QueryerMock{}
+	qqMock.On("Query", context.Background(), nil).
+		Once().
+		Return(nil, sql.ErrNoRows)
+
+	err := QueryExactlyOne(qqMock, context.Background(), nil, func(ro vrows.Rower) error {
+		t.Error("Method should not be called")
+		return nil
+	})
+
+	assert.IsType(t, &ErrRowNotFound{}, err)
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+	qqMock.AssertExpectations(t)
+}
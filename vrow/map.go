@@ -0,0 +1,162 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vrow
+
+import (
+	"context"
+	"database/sql"
+	"github.com/wojnosystems/vsql"
+	"github.com/wojnosystems/vsql/vparam"
+	"github.com/wojnosystems/vsql/vquery"
+	"github.com/wojnosystems/vsql/vrows"
+	"reflect"
+	"strings"
+)
+
+// textTypeNames are parts of database type names for columns holding text, which drivers may return as []byte.
+// This includes numbers and times that drivers such as MySQL's send as text
+var textTypeNames = []string{"CHAR", "TEXT", "CLOB", "JSON", "XML", "ENUM", "UUID", "DECIMAL", "NUMERIC", "DATE", "TIME", "YEAR"}
+
+var nullStringType = reflect.TypeOf(sql.NullString{})
+
+// ScanMap reads the vrow into a map keyed by Columns(), for when the columns aren't known ahead of time, such as admin tools and exports
+//
+// Values are whatever the driver returns. NULLs are nil. []byte is converted to string when ColumnTypes says the column is text, such as a VARCHAR, TEXT or DECIMAL, or that it scans into a string.
+// Otherwise, such as for a BLOB or when the driver does not support ColumnTypes, the []byte is kept
+// @param ro is the vrow to read
+// @return row has an entry for each column. If columns share a name, the last one wins
+// @return err errors from Scan
+func ScanMap(ro vrows.Rower) (row vsql.H, err error) {
+	return newMapScanner(ro).scan(ro)
+}
+
+// EachMap calls eachRow with every vrow, read as with ScanMap. See Each
+func EachMap(r vrows.Rowser, eachRow func(row vsql.H) (stop bool, err error)) (err error) {
+	var s *mapScanner
+	return Each(r, func(ro vrows.Rower) (stop bool, err error) {
+		if s == nil {
+			// every vrow has the same columns, so only work them out once
+			s = newMapScanner(ro)
+		}
+		row, err := s.scan(ro)
+		if err != nil {
+			return true, err
+		}
+		return eachRow(row)
+	})
+}
+
+// QueryEachMap runs the query and calls eachRow with every vrow, read as with ScanMap. See QueryEach
+func QueryEachMap(queryer vquery.Queryer, ctx context.Context, q vparam.Queryer, eachRow func(row vsql.H) (stop bool, err error)) (err error) {
+	var s *mapScanner
+	return QueryEach(queryer, ctx, q, func(ro vrows.Rower) (stop bool, err error) {
+		if s == nil {
+			s = newMapScanner(ro)
+		}
+		row, err := s.scan(ro)
+		if err != nil {
+			return true, err
+		}
+		return eachRow(row)
+	})
+}
+
+// EachValues calls eachRow with the values of every vrow, in the same order as the columns. The values are converted as with ScanMap
+//
+// This is for code that needs to keep the columns in order, such as exporters, without knowing the columns ahead of time
+// @param eachRow is called with the column names and values. The columns are shared by every call and must not be modified
+func EachValues(r vrows.Rowser, eachRow func(columns []string, values []interface{}) (stop bool, err error)) (err error) {
+	var s *mapScanner
+	return Each(r, func(ro vrows.Rower) (stop bool, err error) {
+		if s == nil {
+			s = newMapScanner(ro)
+		}
+		values, err := s.scanValues(ro)
+		if err != nil {
+			return true, err
+		}
+		return eachRow(s.columns, values)
+	})
+}
+
+// mapScanner holds what ScanMap needs to know about the columns
+type mapScanner struct {
+	columns []string
+	// text is true for columns whose []byte should be converted to a string
+	text []bool
+}
+
+func newMapScanner(ro vrows.Rower) *mapScanner {
+	s := &mapScanner{
+		columns: ro.Columns(),
+	}
+	s.text = make([]bool, len(s.columns))
+	// not every driver supports column types. Without them, nothing is known to be text, so the bytes are kept
+	columnTypes, err := ro.ColumnTypes()
+	if err == nil && len(columnTypes) == len(s.columns) {
+		for i, columnType := range columnTypes {
+			s.text[i] = isTextColumn(columnType)
+		}
+	}
+	return s
+}
+
+func (s *mapScanner) scan(ro vrows.Rower) (row vsql.H, err error) {
+	values, err := s.scanValues(ro)
+	if err != nil {
+		return nil, err
+	}
+	row = make(vsql.H, len(s.columns))
+	for i, column := range s.columns {
+		row[column] = values[i]
+	}
+	return row, nil
+}
+
+// scanValues reads the values of the vrow, with text converted to strings
+func (s *mapScanner) scanValues(ro vrows.Rower) (values []interface{}, err error) {
+	values = make([]interface{}, len(s.columns))
+	destinations := make([]interface{}, len(s.columns))
+	for i := range values {
+		destinations[i] = &values[i]
+	}
+	if err = ro.Scan(destinations...); err != nil {
+		return nil, err
+	}
+	for i := range values {
+		if b, ok := values[i].([]byte); ok {
+			if s.text[i] {
+				values[i] = string(b)
+			} else {
+				// drivers may reuse the bytes for the next vrow
+				values[i] = append([]byte(nil), b...)
+			}
+		}
+	}
+	return values, nil
+}
+
+// isTextColumn is true if the database type name is for text, such as "VARCHAR", "TEXT" or "DECIMAL", or if the driver scans the column into a string
+func isTextColumn(columnType vrows.ColumnTyper) bool {
+	typeName := strings.ToUpper(columnType.DatabaseTypeName())
+	for _, textName := range textTypeNames {
+		if strings.Contains(typeName, textName) {
+			return true
+		}
+	}
+	scanType := columnType.ScanType()
+	return scanType != nil && (scanType.Kind() == reflect.String || scanType == nullStringType)
+}
@@ -0,0 +1,112 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vrow
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/wojnosystems/vsql"
+	"github.com/wojnosystems/vsql/vrows"
+	"reflect"
+	"testing"
+)
+
+// newMapRowMock creates a vrow with the columns and database type names, that scans the values into the destinations
+func newMapRowMock(columns []string, typeNames []string, values ...interface{}) *vrows.RowerMock {
+	rowMock := vrows.NewRowerMock(columns, values...)
+	rowMock.On("ColumnTypes").
+		Return(vrows.NewColumnTyperMocks(typeNames...), nil)
+	return rowMock
+}
+
+func TestScanMap(t *testing.T) {
+	rowMock := newMapRowMock(
+		[]string{"id", "name", "avatar", "deleted_at", "balance", "flags"},
+		[]string{"BIGINT", "VARCHAR", "BLOB", "DATETIME", "DECIMAL", "BIT"},
+		int64(1), []byte("bob"), []byte{0xff}, nil, []byte("1.50"), []byte{0x01})
+
+	row, err := ScanMap(rowMock)
+
+	assert.NoError(t, err)
+	assert.Equal(t, vsql.H{
+		"id":         int64(1),
+		"name":       "bob",
+		"avatar":     []byte{0xff},
+		"deleted_at": nil,
+		"balance":    "1.50",
+		"flags":      []byte{0x01},
+	}, row)
+}
+
+func TestScanMap_ScanType(t *testing.T) {
+	rowMock := vrows.NewRowerMock([]string{"name", "data"}, []byte("bob"), []byte{0xff})
+	nameType := &vrows.ColumnTyperMock{}
+	nameType.On("DatabaseTypeName").
+		Return("")
+	nameType.On("ScanType").
+		Return(reflect.TypeOf(""))
+	dataType := &vrows.ColumnTyperMock{}
+	dataType.On("DatabaseTypeName").
+		Return("")
+	dataType.On("ScanType").
+		Return(reflect.TypeOf([]byte{}))
+	rowMock.On("ColumnTypes").
+		Return([]vrows.ColumnTyper{nameType, dataType}, nil)
+
+	row, err := ScanMap(rowMock)
+
+	assert.NoError(t, err)
+	assert.Equal(t, vsql.H{"name": "bob", "data": []byte{0xff}}, row)
+}
+
+func TestScanMap_NoColumnTypes(t *testing.T) {
+	rowMock := vrows.NewRowerMock([]string{"name"}, []byte("bob"))
+	rowMock.On("ColumnTypes").
+		Return(nil, errors.New("not supported"))
+
+	row, err := ScanMap(rowMock)
+
+	assert.NoError(t, err)
+	assert.Equal(t, vsql.H{"name": []byte("bob")}, row)
+}
+
+func TestEachMap(t *testing.T) {
+	rowsMock := vrows.NewRowserMock(
+		newMapRowMock([]string{"name"}, []string{"TEXT"}, []byte("bob")),
+		newMapRowMock([]string{"name"}, []string{"TEXT"}, []byte("alice")),
+	)
+	rows := make([]vsql.H, 0, 2)
+
+	err := EachMap(rowsMock, func(row vsql.H) (stop bool, err error) {
+		rows = append(rows, row)
+		return false, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []vsql.H{{"name": "bob"}, {"name": "alice"}}, rows)
+}
+
+func TestAll_Maps(t *testing.T) {
+	rowsMock := vrows.NewRowserMock(
+		newMapRowMock([]string{"name"}, []string{"TEXT"}, []byte("bob")),
+	)
+	var rows []vsql.H
+
+	err := All(rowsMock, &rows, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []vsql.H{{"name": "bob"}}, rows)
+}
@@ -0,0 +1,91 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vrow
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/wojnosystems/vsql/vparam"
+	"github.com/wojnosystems/vsql/vquery"
+	"github.com/wojnosystems/vsql/vrows"
+)
+
+// EachResultSet is Each for queries that return more than one result set, such as stored procedures or several statements sent at once
+//
+// EachResultSet will also handle cleaning up the Rowser record when it returns, preventing memory leaks
+//
+// @param r comes from Query() calls
+// @param eachSet has one predicate per result set, called for each vrow in that result set, in order. Returning true, nil skips the rest of that result set and moves on to the next one. If you return an error, iteration stops and it is passed to the caller
+// @return err the database error encountered, an *ErrMissingResultSet if there were fewer result sets than predicates, or the error returned by a predicate
+//
+// Result sets after the last predicate are ignored, as some databases, such as MySQL, add a status result set at the end of a stored procedure call
+//
+// Example:
+//   err := vrow.QueryEachResultSet(db, ctx, vparam.New("CALL user_with_groups(1)"),
+//     func(ro vrows.Rower) (stop bool, err error) {
+//       return true, vrow.ScanStruct(ro, &user)
+//     },
+//     func(ro vrows.Rower) (stop bool, err error) {
+//       g := Group{}
+//       err = vrow.ScanStruct(ro, &g)
+//       groups = append(groups, g)
+//       return false, err
+//     })
+func EachResultSet(r vrows.Rowser, eachSet ...func(ro vrows.Rower) (stop bool, err error)) (err error) {
+	defer func() { _ = r.Close() }()
+	for i, eachRow := range eachSet {
+		if i != 0 && !r.NextResultSet() {
+			if err = r.Err(); err != nil {
+				return err
+			}
+			return &ErrMissingResultSet{index: i, expected: len(eachSet)}
+		}
+		if err = eachInResultSet(r, eachRow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueryEachResultSet runs the query and calls a predicate for the vrows of each result set. See EachResultSet
+func QueryEachResultSet(queryer vquery.Queryer, ctx context.Context, q vparam.Queryer, eachSet ...func(ro vrows.Rower) (stop bool, err error)) (err error) {
+	var qr vrows.Rowser
+	qr, err = queryer.Query(ctx, q)
+	if err == sql.ErrNoRows {
+		// hide the noRows error, dumb interface decision to use error for this state... :(
+		if qr != nil {
+			_ = qr.Close()
+		}
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	return EachResultSet(qr, eachSet...)
+}
+
+// ErrMissingResultSet is returned by EachResultSet when the query returned fewer result sets than there were predicates
+type ErrMissingResultSet struct {
+	// index is the 0-based index of the first result set that was missing
+	index    int
+	expected int
+}
+
+// Error satisfies the Error interface and says how many result sets were returned
+func (e ErrMissingResultSet) Error() string {
+	return fmt.Sprintf("query returned %d result sets, expected %d", e.index, e.expected)
+}
@@ -0,0 +1,83 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vrow
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wojnosystems/vsql/vrows"
+	"testing"
+)
+
+// newResultSetsMock creates a Rowser that returns the number of vrows in each result set
+func newResultSetsMock(rowsPerSet ...int) *vrows.RowserMock {
+	sets := make([][]vrows.Rower, len(rowsPerSet))
+	for i, rows := range rowsPerSet {
+		for j := 0; j < rows; j++ {
+			sets[i] = append(sets[i], &vrows.RowerMock{})
+		}
+	}
+	return vrows.NewResultSetsRowserMock(sets...)
+}
+
+func TestEachResultSet(t *testing.T) {
+	cases := map[string]struct {
+		rowsPerSet []int
+		sets       int
+		expected   []int
+		err        error
+	}{
+		"one": {
+			rowsPerSet: []int{2},
+			sets:       1,
+			expected:   []int{2},
+		},
+		"two": {
+			rowsPerSet: []int{1, 3},
+			sets:       2,
+			expected:   []int{1, 3},
+		},
+		"trailing status set ignored": {
+			rowsPerSet: []int{1, 3, 0},
+			sets:       2,
+			expected:   []int{1, 3},
+		},
+		"missing set": {
+			rowsPerSet: []int{1},
+			sets:       2,
+			expected:   []int{1, 0},
+			err:        &ErrMissingResultSet{index: 1, expected: 2},
+		},
+	}
+
+	for caseName, c := range cases {
+		rowsMock := newResultSetsMock(c.rowsPerSet...)
+		counts := make([]int, c.sets)
+		eachSet := make([]func(ro vrows.Rower) (stop bool, err error), c.sets)
+		for i := range eachSet {
+			set := i
+			eachSet[i] = func(ro vrows.Rower) (stop bool, err error) {
+				counts[set]++
+				return false, nil
+			}
+		}
+
+		err := EachResultSet(rowsMock, eachSet...)
+
+		assert.Equal(t, c.err, err, caseName)
+		assert.Equal(t, c.expected, counts, caseName)
+		rowsMock.AssertCalled(t, "Close")
+	}
+}
@@ -29,7 +29,7 @@ import (
 //
 // @vparam r comes from Query() calls
 // @vparam eachRow is a predicate to call for each vrow encountered. Return false, nil to keep going. Return true, nil to stop the loop and clean up the vrow. If you return an error, that error will be passed to the caller of Each and iteration will stop as well.
-// @return err the database error encountered or that was returned from eachRow
+// @return err the database error encountered, including those that ended iteration early (see vrows.Rowser.Err), or that was returned from eachRow
 //
 // this will not return that annoying sql.ErrNoRows error. Usually, you're building an array with this and NoRows is not an error, but a valid state.
 //
@@ -47,6 +47,11 @@ import (
 //   } )
 func Each(r vrows.Rowser, eachRow func(ro vrows.Rower) (stop bool, err error)) (err error) {
 	defer func() { _ = r.Close() }()
+	return eachInResultSet(r, eachRow)
+}
+
+// eachInResultSet calls eachRow for the vrows of the current result set, like Each, but does not close r
+func eachInResultSet(r vrows.Rowser, eachRow func(ro vrows.Rower) (stop bool, err error)) (err error) {
 	var ro vrows.Rower
 	for {
 		ro = r.Next()
@@ -61,7 +66,8 @@ func Each(r vrows.Rowser, eachRow func(ro vrows.Rower) (stop bool, err error)) (
 			}
 		}
 	}
-	return
+	// Next stops on errors as well as at the end of the vrows
+	return r.Err()
 }
 
 func QueryEach(queryer vquery.Queryer, ctx context.Context, q vparam.Queryer, eachRow func(ro vrows.Rower) (cont bool, err error)) (err error) {
@@ -88,15 +94,15 @@ func QueryEach(queryer vquery.Queryer, ctx context.Context, q vparam.Queryer, ea
 // @vparam r comes from Query() calls
 // @vparam theRow is a predicate to call for the top vrow encountered. If you return an error, that error will be passed to the caller of One
 // @return ok true if the database returned at least 1 vresult, false if nothing was returned
-// @return err the database error encountered or that was returned from eachRow
+// @return err the database error encountered, including those that prevented the first vrow from being read, or that was returned from theRow
 //
 // this will not return that annoying sql.ErrNoRows error. Usually, you're building an array with this and NoRows is not an error, but a valid state.
 func One(r vrows.Rowser, theRow func(ro vrows.Rower) (err error)) (ok bool, err error) {
 	defer func() { _ = r.Close() }()
 	ro := r.Next()
 	if ro == nil {
-		// nothing returned
-		return false, nil
+		// nothing returned, or an error prevented the first vrow from being read
+		return false, r.Err()
 	} else {
 		err = theRow(ro)
 	}
@@ -73,6 +73,40 @@ func TestEachRow_EachRowStopCont(t *testing.T) {
 	rowsMock.AssertExpectations(t)
 }
 
+func TestEachRow_ErrorIterating(t *testing.T) {
+	// Setup
+	errExpected := errors.New("connection lost")
+	rowsMock := &vrows.RowserMock{}
+	rowsMock.On("Next").
+		Once().
+		Return(&vrows.RowerMock{})
+	rowsMock.On("Next").
+		Once().
+		Return(nil)
+	rowsMock.On("Err").
+		Once().
+		Return(errExpected)
+	rowsMock.On("Close").
+		Once().
+		Return(nil)
+	i := 0
+
+	// Perform
+	err := Each(rowsMock, func(ro vrows.Rower) (stop bool, err error) {
+		i++
+		return false, nil
+	})
+
+	// Assert
+	if err != errExpected {
+		t.Error("expected the iteration error to be passed through")
+	}
+	if i != 1 {
+		t.Error("expected the row before the error to be handled")
+	}
+	rowsMock.AssertExpectations(t)
+}
+
 func TestQueryEach_NoRows(t *testing.T) {
 	// Setup
 	errExpected := sql.ErrNoRows
@@ -128,6 +162,9 @@ func TestOneRow_NoResults(t *testing.T) {
 	rowsMock.On("Next").
 		Once().
 		Return(nil)
+	rowsMock.On("Err").
+		Once().
+		Return(nil)
 	rowsMock.On("Close").
 		Once().
 		Return(nil)
@@ -148,6 +185,36 @@ func TestOneRow_NoResults(t *testing.T) {
 	rowsMock.AssertExpectations(t)
 }
 
+func TestOneRow_ErrorIterating(t *testing.T) {
+	// Setup
+	errExpected := errors.New("context canceled")
+	rowsMock := &vrows.RowserMock{}
+	rowsMock.On("Next").
+		Once().
+		Return(nil)
+	rowsMock.On("Err").
+		Once().
+		Return(errExpected)
+	rowsMock.On("Close").
+		Once().
+		Return(nil)
+
+	// Perform
+	ok, err := One(rowsMock, func(ro vrows.Rower) (err error) {
+		t.Error("Method should not be called")
+		return nil
+	})
+
+	// Assert
+	if err != errExpected {
+		t.Error("expected the iteration error to be passed through")
+	}
+	if ok {
+		t.Error("expected no vrows, should not be OK")
+	}
+	rowsMock.AssertExpectations(t)
+}
+
 func TestQueryOne_NoResults(t *testing.T) {
 	// Setup
 	errExpected := sql.ErrNoRows
@@ -0,0 +1,129 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vrow
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/wojnosystems/vsql/vparam"
+	"github.com/wojnosystems/vsql/vquery"
+	"github.com/wojnosystems/vsql/vrows"
+	"reflect"
+)
+
+// The helpers in this file handle NULL the same way: pointers, interface{} and sql.Scanner destinations receive the NULL as-is, such as nil for *string and Valid: false for sql.NullString.
+// Everything else, which the driver would refuse to scan a NULL into, is set to its zero value
+
+// QueryScalar runs the query and scans the only column of the first vrow into dest, such as for: `SELECT name FROM users WHERE id = ?`
+// @param dest is a pointer to the value to set
+// @return ok true if the database returned at least 1 vrow, false if nothing was returned. dest is not changed when nothing was returned
+// @return err the database error encountered
+// @example
+//   var name string
+//   ok, err := vrow.QueryScalar(db, ctx, vparam.NewAppendWithData("select name from users where id = ?", id), &name)
+func QueryScalar(queryer vquery.Queryer, ctx context.Context, q vparam.Queryer, dest interface{}) (ok bool, err error) {
+	target, finish, err := nullableScanTarget(dest)
+	if err != nil {
+		return false, err
+	}
+	ok, err = QueryOne(queryer, ctx, q, func(ro vrows.Rower) (err error) {
+		if err = ro.Scan(target); err == nil {
+			finish()
+		}
+		return
+	})
+	return
+}
+
+// QueryColumn runs the query and collects the only column of every vrow into a slice, such as for: `SELECT id FROM users WHERE team_id = ?`
+// @param dest is a pointer to the slice. Its contents are replaced
+// @return err the database error encountered. Having no vrows is not an error, dest is set to an empty slice
+// @example
+//   var ids []int64
+//   err := vrow.QueryColumn(db, ctx, vparam.NewAppendWithData("select id from users where team_id = ?", teamID), &ids)
+func QueryColumn(queryer vquery.Queryer, ctx context.Context, q vparam.Queryer, dest interface{}) (err error) {
+	return QueryAll(queryer, ctx, q, dest, scanNullable)
+}
+
+// QueryMap runs the query and collects the first column of each vrow as the key and the second as the value, such as for: `SELECT id, name FROM users`
+//
+// If a key is repeated, the value from the last vrow with that key is kept
+// @param dest is a pointer to the map. If the map is nil, one is made. Existing entries are kept unless overwritten
+// @return err the database error encountered. Having no vrows is not an error
+// @example
+//   namesByID := make(map[int64]string)
+//   err := vrow.QueryMap(db, ctx, vparam.New("select id, name from users"), &namesByID)
+func QueryMap(queryer vquery.Queryer, ctx context.Context, q vparam.Queryer, dest interface{}) (err error) {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("vrow: QueryMap requires a non-nil pointer to a map, got %T", dest)
+	}
+	m := rv.Elem()
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	return QueryEach(queryer, ctx, q, func(ro vrows.Rower) (stop bool, err error) {
+		key := reflect.New(m.Type().Key())
+		value := reflect.New(m.Type().Elem())
+		keyTarget, keyFinish, _ := nullableScanTarget(key.Interface())
+		valueTarget, valueFinish, _ := nullableScanTarget(value.Interface())
+		if err = ro.Scan(keyTarget, valueTarget); err != nil {
+			return true, err
+		}
+		keyFinish()
+		valueFinish()
+		m.SetMapIndex(key.Elem(), value.Elem())
+		return false, nil
+	})
+}
+
+// scanNullable is a ScanFunc for a single column, with NULLs handled like QueryScalar
+func scanNullable(ro vrows.Rower, dest interface{}) (err error) {
+	target, finish, err := nullableScanTarget(dest)
+	if err != nil {
+		return err
+	}
+	if err = ro.Scan(target); err == nil {
+		finish()
+	}
+	return
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// nullableScanTarget works out what to pass to Scan so that a NULL can be scanned into dest
+// @param dest is a non-nil pointer
+// @return target is dest if it can already hold a NULL, otherwise a pointer to a pointer of dest's type
+// @return finish copies what was scanned into target into dest. Call it after a successful Scan
+func nullableScanTarget(dest interface{}) (target interface{}, finish func(), err error) {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, nil, fmt.Errorf("vrow: scan destination must be a non-nil pointer, got %T", dest)
+	}
+	t := rv.Type().Elem()
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface || rv.Type().Implements(scannerType) {
+		return dest, func() {}, nil
+	}
+	holder := reflect.New(reflect.PtrTo(t))
+	return holder.Interface(), func() {
+		if holder.Elem().IsNil() {
+			rv.Elem().Set(reflect.Zero(t))
+		} else {
+			rv.Elem().Set(holder.Elem().Elem())
+		}
+	}, nil
+}
@@ -0,0 +1,98 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vrow
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/wojnosystems/vsql/vquery"
+	"github.com/wojnosystems/vsql/vrows"
+	"testing"
+)
+
+// newQueryerMock creates a Queryer that returns the vrows
+func newQueryerMock(rows ...vrows.Rower) *vquery.QueryerMock {
+	qqMock := &vquery.QueryerMock{}
+	qqMock.On("Query", context.Background(), nil).
+		Once().
+		Return(vrows.NewRowserMock(rows...), nil)
+	return qqMock
+}
+
+func TestQueryScalar(t *testing.T) {
+	name := "previous"
+	ok, err := QueryScalar(newQueryerMock(vrows.NewRowerMock(nil, "bob")), context.Background(), nil, &name)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "bob", name)
+
+	ok, err = QueryScalar(newQueryerMock(vrows.NewRowerMock(nil, nil)), context.Background(), nil, &name)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "", name)
+
+	namePtr := &name
+	ok, err = QueryScalar(newQueryerMock(vrows.NewRowerMock(nil, nil)), context.Background(), nil, &namePtr)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Nil(t, namePtr)
+
+	name = "previous"
+	ok, err = QueryScalar(newQueryerMock(), context.Background(), nil, &name)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "previous", name)
+}
+
+func TestQueryScalar_Scanner(t *testing.T) {
+	rowMock := &vrows.RowerMock{}
+	rowMock.On("Scan", mock.Anything).
+		Return(nil)
+	rowMock.ScanMock = func(values ...interface{}) {
+		_ = values[0].(sql.Scanner).Scan(nil)
+	}
+	name := sql.NullString{String: "previous", Valid: true}
+	ok, err := QueryScalar(newQueryerMock(rowMock), context.Background(), nil, &name)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, sql.NullString{}, name)
+}
+
+func TestQueryColumn(t *testing.T) {
+	var ids []int64
+	err := QueryColumn(newQueryerMock(
+		vrows.NewRowerMock(nil, int64(1)),
+		vrows.NewRowerMock(nil, nil),
+		vrows.NewRowerMock(nil, int64(3)),
+	), context.Background(), nil, &ids)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 0, 3}, ids)
+}
+
+func TestQueryMap(t *testing.T) {
+	var namesByID map[int64]*string
+	err := QueryMap(newQueryerMock(
+		vrows.NewRowerMock(nil, int64(1), "bob"),
+		vrows.NewRowerMock(nil, int64(2), nil),
+	), context.Background(), nil, &namesByID)
+	assert.NoError(t, err)
+	bob := "bob"
+	assert.Equal(t, map[int64]*string{1: &bob, 2: nil}, namesByID)
+
+	assert.Error(t, QueryMap(newQueryerMock(), context.Background(), nil, namesByID))
+}
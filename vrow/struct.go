@@ -0,0 +1,158 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vrow
+
+import (
+	"fmt"
+	"github.com/wojnosystems/vsql/vrows"
+	"github.com/wojnosystems/vsql/vstruct"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ColumnPolicy is what ScanStruct does when the columns of the vrow and the fields of the struct don't line up
+type ColumnPolicy int
+
+const (
+	// ColumnIgnore skips the column or field
+	ColumnIgnore ColumnPolicy = iota
+	// ColumnError fails the scan with ErrUnknownColumn or ErrMissingColumn
+	ColumnError
+)
+
+// ScanOptions configures ScanStructWith
+type ScanOptions struct {
+	// UnknownColumns is what to do with columns that have no `db` tagged field
+	UnknownColumns ColumnPolicy
+	// MissingColumns is what to do with `db` tagged fields that have no column. These fields are left as they were
+	MissingColumns ColumnPolicy
+}
+
+// DefaultScanOptions are used by ScanStruct. Unknown columns are errors, as they usually mean a typo in a tag or a SELECT *, while missing columns are fine, so one struct can be used by queries selecting different columns
+var DefaultScanOptions = ScanOptions{
+	UnknownColumns: ColumnError,
+	MissingColumns: ColumnIgnore,
+}
+
+// ScanStruct reads the vrow into the `db:"column"` tagged fields of a struct, matching them up by Columns(), so that changing the column order of a query doesn't break it
+//
+// Untagged embedded structs are scanned as if their fields were part of dest, and nil embedded pointers are allocated. Pointer fields are set to nil for NULL.
+// The mapping of columns to fields is cached per struct type and set of columns
+// @param ro is the vrow to read
+// @param dest is a pointer to the struct to fill in
+// @return err ErrUnknownColumn for columns without a field, or errors from Scan
+// @example
+//   type User struct { Name string `db:"name"`; Age *int `db:"age"` }
+//   err := vrow.QueryEach(db, ctx, vparam.New("select age, name from users"), func(ro vrows.Rower) (stop bool, err error) {
+//     u := User{}
+//     err = vrow.ScanStruct(ro, &u)
+//     users = append(users, u)
+//     return false, err
+//   })
+func ScanStruct(ro vrows.Rower, dest interface{}) (err error) {
+	return ScanStructWith(ro, dest, DefaultScanOptions)
+}
+
+// ScanStructWith is ScanStruct, but with control over what happens when columns and fields don't match
+func ScanStructWith(ro vrows.Rower, dest interface{}, opts ScanOptions) (err error) {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("vrow: ScanStruct requires a non-nil pointer to a struct, got %T", dest)
+	}
+	rv = rv.Elem()
+	columns := ro.Columns()
+	plan, err := structScanPlanFor(rv.Type(), columns, opts)
+	if err != nil {
+		return err
+	}
+	destinations := make([]interface{}, len(plan))
+	for i, index := range plan {
+		if index == nil {
+			destinations[i] = new(interface{})
+			continue
+		}
+		field, ok := vstruct.FieldByIndexAlloc(rv, index)
+		if !ok {
+			return fmt.Errorf(`vrow: cannot scan column "%s" into %s: it is in an unexported embedded struct pointer that is nil`, columns[i], rv.Type())
+		}
+		destinations[i] = field.Addr().Interface()
+	}
+	return ro.Scan(destinations...)
+}
+
+// structScanKey identifies a cached plan
+type structScanKey struct {
+	t       reflect.Type
+	columns string
+	opts    ScanOptions
+}
+
+// structScanPlans caches the []([]int) field index for each column, nil if the column is discarded, by structScanKey
+var structScanPlans sync.Map
+
+func structScanPlanFor(t reflect.Type, columns []string, opts ScanOptions) (plan [][]int, err error) {
+	key := structScanKey{t: t, columns: strings.Join(columns, "\x00"), opts: opts}
+	if cached, ok := structScanPlans.Load(key); ok {
+		return cached.([][]int), nil
+	}
+	fields := vstruct.Fields(t)
+	byName := make(map[string][]int, len(fields))
+	for _, f := range fields {
+		byName[f.Name] = f.Index
+	}
+	plan = make([][]int, len(columns))
+	found := make(map[string]bool, len(columns))
+	for i, column := range columns {
+		index, ok := byName[column]
+		if !ok && opts.UnknownColumns == ColumnError {
+			return nil, &ErrUnknownColumn{column: column, t: t}
+		}
+		plan[i] = index
+		found[column] = true
+	}
+	if opts.MissingColumns == ColumnError {
+		for _, f := range fields {
+			if !found[f.Name] {
+				return nil, &ErrMissingColumn{column: f.Name, t: t}
+			}
+		}
+	}
+	structScanPlans.Store(key, plan)
+	return plan, nil
+}
+
+// ErrUnknownColumn is returned when a column has no `db` tagged field to scan into
+type ErrUnknownColumn struct {
+	column string
+	t      reflect.Type
+}
+
+// Error satisfies the Error interface and says which column had nowhere to go
+func (e ErrUnknownColumn) Error() string {
+	return fmt.Sprintf(`column "%s" has no field tagged with db:"%s" in %s`, e.column, e.column, e.t)
+}
+
+// ErrMissingColumn is returned when a `db` tagged field has no column to scan from and ScanOptions.MissingColumns is ColumnError
+type ErrMissingColumn struct {
+	column string
+	t      reflect.Type
+}
+
+// Error satisfies the Error interface and says which field was not in the results
+func (e ErrMissingColumn) Error() string {
+	return fmt.Sprintf(`field tagged with db:"%s" in %s has no column in the results`, e.column, e.t)
+}
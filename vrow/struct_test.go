@@ -0,0 +1,72 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vrow
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wojnosystems/vsql/vrows"
+	"testing"
+)
+
+type TestAudit struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type testUser struct {
+	*TestAudit
+	ID   int    `db:"id"`
+	Name string `db:"name"`
+	Age  *int   `db:"age"`
+	Note string `db:"-"`
+}
+
+func TestScanStruct(t *testing.T) {
+	rowMock := vrows.NewRowerMock([]string{"name", "created_by", "age", "id"}, "bob", "admin", 21, 5)
+	u := testUser{}
+	err := ScanStruct(rowMock, &u)
+	assert.NoError(t, err)
+	age := 21
+	assert.Equal(t, testUser{TestAudit: &TestAudit{CreatedBy: "admin"}, ID: 5, Name: "bob", Age: &age}, u)
+	rowMock.AssertExpectations(t)
+}
+
+func TestScanStruct_UnknownColumn(t *testing.T) {
+	rowMock := vrows.NewRowerMock([]string{"name", "email"}, "bob", "bob@example.com")
+	u := testUser{}
+	err := ScanStruct(rowMock, &u)
+	assert.EqualError(t, err, `column "email" has no field tagged with db:"email" in vrow.testUser`)
+
+	err = ScanStructWith(rowMock, &u, ScanOptions{UnknownColumns: ColumnIgnore})
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", u.Name)
+}
+
+func TestScanStruct_MissingColumn(t *testing.T) {
+	rowMock := vrows.NewRowerMock([]string{"name", "id"}, "bob", 5)
+	u := testUser{}
+	err := ScanStructWith(rowMock, &u, ScanOptions{MissingColumns: ColumnError})
+	assert.EqualError(t, err, `field tagged with db:"age" in vrow.testUser has no column in the results`)
+
+	err = ScanStruct(rowMock, &u)
+	assert.NoError(t, err)
+	assert.Equal(t, testUser{ID: 5, Name: "bob"}, u)
+}
+
+func TestScanStruct_NotStructPointer(t *testing.T) {
+	rowMock := &vrows.RowerMock{}
+	assert.Error(t, ScanStruct(rowMock, testUser{}))
+	assert.Error(t, ScanStruct(rowMock, (*testUser)(nil)))
+}
@@ -0,0 +1,47 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vrows
+
+import (
+	"reflect"
+)
+
+// ColumnTyper describes a column of the results, as far as the driver knows. *sql.ColumnType satisfies this interface
+//
+// Drivers don't have to support every property, which is what the ok results report
+type ColumnTyper interface {
+	// Name is the name or alias of the column
+	Name() string
+
+	// DatabaseTypeName is the database's name for the type, without the length, such as "VARCHAR", "INT" or "DECIMAL"
+	// @return typeName empty if the driver does not support it
+	DatabaseTypeName() (typeName string)
+
+	// Nullable reports whether the column may be NULL
+	// @return ok false if the driver does not know
+	Nullable() (nullable, ok bool)
+
+	// Length is the length of variable length types such as text and binary
+	// @return ok false if the type has no length or the driver does not know it
+	Length() (length int64, ok bool)
+
+	// DecimalSize is the precision and scale of decimal types
+	// @return ok false if the type is not a decimal or the driver does not know it
+	DecimalSize() (precision, scale int64, ok bool)
+
+	// ScanType is a Go type that is suitable for scanning this column into
+	ScanType() reflect.Type
+}
@@ -15,12 +15,66 @@
 
 package vrows
 
-import "github.com/stretchr/testify/mock"
+import (
+	"database/sql"
+	"github.com/stretchr/testify/mock"
+	"reflect"
+)
 
 type RowserMock struct {
 	mock.Mock
 }
 
+// NewRowserMock creates rows that return each of the rows, then end without error
+// @param rows are returned by Next, in order
+// @return the mock. Close must be called once
+func NewRowserMock(rows ...Rower) *RowserMock {
+	m := &RowserMock{}
+	for _, row := range rows {
+		m.On("Next").
+			Once().
+			Return(row)
+	}
+	m.On("Next").
+		Return(nil)
+	m.On("Err").
+		Return(nil)
+	m.On("Close").
+		Once().
+		Return(nil)
+	return m
+}
+
+// NewResultSetsRowserMock creates rows with a result set for each of sets, then end without error
+// @param sets are the rows of each result set, in order
+// @return the mock. Close must be called once
+func NewResultSetsRowserMock(sets ...[]Rower) *RowserMock {
+	m := &RowserMock{}
+	for i, rows := range sets {
+		if i != 0 {
+			m.On("NextResultSet").
+				Once().
+				Return(true)
+		}
+		for _, row := range rows {
+			m.On("Next").
+				Once().
+				Return(row)
+		}
+		m.On("Next").
+			Once().
+			Return(nil)
+	}
+	m.On("NextResultSet").
+		Return(false)
+	m.On("Err").
+		Return(nil)
+	m.On("Close").
+		Once().
+		Return(nil)
+	return m
+}
+
 func (m *RowserMock) Next() Rower {
 	a := m.Called()
 	r := a.Get(0)
@@ -29,6 +83,14 @@ func (m *RowserMock) Next() Rower {
 	}
 	return r.(Rower)
 }
+func (m *RowserMock) Err() error {
+	a := m.Called()
+	return a.Error(0)
+}
+func (m *RowserMock) NextResultSet() bool {
+	a := m.Called()
+	return a.Bool(0)
+}
 func (m *RowserMock) Close() error {
 	a := m.Called()
 	return a.Error(0)
@@ -40,6 +102,45 @@ type RowerMock struct {
 	ScanMock func(values ...interface{})
 }
 
+// NewRowerMock creates a row that scans the values into the destinations the way database/sql does: NULL (nil) sets the destination to its zero value, pointers are allocated and sql.Scanners scan the value themselves
+// @param columns are returned by Columns. If nil, Columns is not mocked
+// @param values are scanned into the destinations, in order
+// @return the mock. ColumnTypes is not mocked
+func NewRowerMock(columns []string, values ...interface{}) *RowerMock {
+	m := &RowerMock{}
+	if columns != nil {
+		m.On("Columns").
+			Return(columns)
+	}
+	m.On("Scan", mock.Anything).
+		Return(nil)
+	m.ScanMock = func(destinations ...interface{}) {
+		for i, d := range destinations {
+			scanValue(d, values[i])
+		}
+	}
+	return m
+}
+
+// scanValue copies value into the pointer d, as Scan would
+func scanValue(d interface{}, value interface{}) {
+	if scanner, ok := d.(sql.Scanner); ok {
+		_ = scanner.Scan(value)
+		return
+	}
+	dest := reflect.ValueOf(d).Elem()
+	switch {
+	case value == nil:
+		dest.Set(reflect.Zero(dest.Type()))
+	case dest.Kind() == reflect.Ptr:
+		v := reflect.New(dest.Type().Elem())
+		v.Elem().Set(reflect.ValueOf(value))
+		dest.Set(v)
+	default:
+		dest.Set(reflect.ValueOf(value))
+	}
+}
+
 func (m *RowerMock) Scan(values ...interface{}) error {
 	a := m.Called(values)
 	if m.ScanMock != nil {
@@ -52,3 +153,66 @@ func (m *RowerMock) Columns() (columnNames []string) {
 	a := m.Called()
 	return a.Get(0).([]string)
 }
+
+func (m *RowerMock) ColumnTypes() (columnTypes []ColumnTyper, err error) {
+	a := m.Called()
+	r := a.Get(0)
+	if r == nil {
+		return nil, a.Error(1)
+	}
+	return r.([]ColumnTyper), a.Error(1)
+}
+
+type ColumnTyperMock struct {
+	mock.Mock
+}
+
+// NewColumnTyperMocks creates a column type for each of the database type names. The driver does not know the ScanType of any column
+// @param typeNames are returned by DatabaseTypeName, e.g. VARCHAR
+// @return the column types, ready to be returned by RowerMock.ColumnTypes
+func NewColumnTyperMocks(typeNames ...string) []ColumnTyper {
+	columnTypes := make([]ColumnTyper, len(typeNames))
+	for i, typeName := range typeNames {
+		columnType := &ColumnTyperMock{}
+		columnType.On("DatabaseTypeName").
+			Return(typeName)
+		columnType.On("ScanType").
+			Return(nil)
+		columnTypes[i] = columnType
+	}
+	return columnTypes
+}
+
+func (m *ColumnTyperMock) Name() string {
+	a := m.Called()
+	return a.String(0)
+}
+
+func (m *ColumnTyperMock) DatabaseTypeName() string {
+	a := m.Called()
+	return a.String(0)
+}
+
+func (m *ColumnTyperMock) Nullable() (nullable, ok bool) {
+	a := m.Called()
+	return a.Bool(0), a.Bool(1)
+}
+
+func (m *ColumnTyperMock) Length() (length int64, ok bool) {
+	a := m.Called()
+	return a.Get(0).(int64), a.Bool(1)
+}
+
+func (m *ColumnTyperMock) DecimalSize() (precision, scale int64, ok bool) {
+	a := m.Called()
+	return a.Get(0).(int64), a.Get(1).(int64), a.Bool(2)
+}
+
+func (m *ColumnTyperMock) ScanType() reflect.Type {
+	a := m.Called()
+	r := a.Get(0)
+	if r == nil {
+		return nil
+	}
+	return r.(reflect.Type)
+}
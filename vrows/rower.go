@@ -21,8 +21,15 @@ import (
 
 type Rowser interface {
 	// Next gets the first/next vrow.
-	// @return vrow, the next vrow, or nil if no more vrows are available
+	// @return vrow, the next vrow, or nil if no more vrows are available or an error was encountered. Check Err to tell the two apart
 	Next() (row Rower)
+	// Err is the error, if any, that stopped Next from returning more vrows, such as a lost connection or a cancelled context
+	// @return err nil if Next returned nil because all of the vrows were read
+	Err() (err error)
+	// NextResultSet moves on to the next result set, for queries that return more than one, such as stored procedures. Any vrows left in the current result set are skipped
+	// Next must be called to get the first vrow of the new result set
+	// @return ok false if there are no more result sets or an error was encountered. Check Err to tell the two apart
+	NextResultSet() (ok bool)
 	io.Closer
 }
 
@@ -30,6 +37,9 @@ type Rower interface {
 	// Columns returns a list of columns available for this vrow
 	Columns() (columnNames []string)
 
+	// ColumnTypes describes the type of each column available for this vrow, in the same order as Columns
+	ColumnTypes() (columnTypes []ColumnTyper, err error)
+
 	// Scan reads values from the vresult and inserts them into the pointers passed in as arguments
 	Scan(destination ...interface{}) (err error)
 }
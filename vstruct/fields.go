@@ -0,0 +1,177 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package vstruct reads the `db:"column"` tags of structs so that values can be moved between structs and queries.
+// The metadata is computed once per type and cached, as reflection is slow and these are used on hot paths
+package vstruct
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TagName is the struct tag key that holds the column name
+const TagName = "db"
+
+// Field describes a tagged field of a struct
+type Field struct {
+	// Name is the column name from the tag
+	Name string
+	// Index is the path of field indexes from the outer struct to this field, as used by reflect.Value.FieldByIndex. Fields of embedded structs have more than one
+	Index []int
+	// OmitEmpty is true if the tag had the omitempty option, e.g. `db:"name,omitempty"`
+	OmitEmpty bool
+}
+
+// fieldCache holds the []Field for each reflect.Type already inspected
+var fieldCache sync.Map
+
+// Fields lists the tagged fields of a struct type, in declaration order.
+//
+// Only fields with a db tag are listed, and `db:"-"` is skipped. Untagged embedded structs, or pointers to structs, are flattened into the outer struct.
+// If a name is used more than once, the least-nested field wins, just like encoding/json
+// @param t must be a struct type
+// @return fields the tagged fields. Do not modify this, it is shared
+func Fields(t reflect.Type) (fields []Field) {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]Field)
+	}
+	fields = collectFields(t)
+	fieldCache.Store(t, fields)
+	return
+}
+
+// collectFields walks the struct one level of embedding at a time, so shallower fields are seen first
+func collectFields(t reflect.Type) (fields []Field) {
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+	seen := make(map[string]bool)
+	visited := make(map[reflect.Type]bool)
+	level := []embedded{{t: t}}
+	for len(level) != 0 {
+		var next []embedded
+		// names found at this level. Fields at the same depth with the same name are ambiguous and both are dropped
+		found := make(map[string]int)
+		var levelFields []Field
+		for _, e := range level {
+			if visited[e.t] {
+				continue
+			}
+			visited[e.t] = true
+			for i := 0; i < e.t.NumField(); i++ {
+				sf := e.t.Field(i)
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+				tag, hasTag := sf.Tag.Lookup(TagName)
+				if !hasTag {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if sf.Anonymous && ft.Kind() == reflect.Struct {
+						next = append(next, embedded{t: ft, index: index})
+					}
+					continue
+				}
+				if sf.PkgPath != "" {
+					// unexported fields cannot be read or set. Untagged embedded structs were flattened above, as their exported fields can be
+					continue
+				}
+				name, options := parseTag(tag)
+				if name == "-" || name == "" || seen[name] {
+					continue
+				}
+				found[name]++
+				levelFields = append(levelFields, Field{
+					Name:      name,
+					Index:     index,
+					OmitEmpty: options.contains("omitempty"),
+				})
+			}
+		}
+		for _, f := range levelFields {
+			if found[f.Name] == 1 {
+				fields = append(fields, f)
+			}
+		}
+		for name := range found {
+			seen[name] = true
+		}
+		level = next
+	}
+	return
+}
+
+// tagOptions are the comma-separated options after the name in a tag
+type tagOptions string
+
+func parseTag(tag string) (name string, options tagOptions) {
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		return tag[:i], tagOptions(tag[i+1:])
+	}
+	return tag, ""
+}
+
+func (o tagOptions) contains(option string) bool {
+	for _, opt := range strings.Split(string(o), ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldByIndex is like reflect.Value.FieldByIndex, but instead of panicking on a nil embedded struct pointer, it returns ok = false
+// @param v is the struct value
+// @param index is the Field.Index to follow
+// @return field the field, if ok
+// @return ok false if a nil embedded pointer was in the way
+func FieldByIndex(v reflect.Value, index []int) (field reflect.Value, ok bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// FieldByIndexAlloc is like reflect.Value.FieldByIndex, but allocates nil embedded struct pointers on the way, so the field can be set
+// @param v is the addressable struct value
+// @param index is the Field.Index to follow
+// @return field the settable field
+// @return ok false if a nil embedded pointer could not be allocated because it is unexported
+func FieldByIndexAlloc(v reflect.Value, index []int) (field reflect.Value, ok bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
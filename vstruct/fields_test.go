@@ -0,0 +1,99 @@
+//Copyright 2019 Chris Wojno
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of this software and associated
+// documentation files (the "Software"), to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all copies or substantial portions of the
+// Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE
+// WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS
+// OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR
+// OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package vstruct
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+type testBase struct {
+	ID      int `db:"id"`
+	Created int `db:"created"`
+}
+
+type testAudit struct {
+	Created int    `db:"created"`
+	By      string `db:"by"`
+}
+
+type testTagged struct {
+	Tag string `db:"tag"`
+}
+
+type testUser struct {
+	testBase
+	*testAudit
+	testTagged `db:"tagged"`
+	Name       string  `db:"name"`
+	Nickname   *string `db:"nickname,omitempty"`
+	Ignored    string  `db:"-"`
+	Untagged   string
+	secret     string `db:"secret"`
+}
+
+func TestFields(t *testing.T) {
+	fields := Fields(reflect.TypeOf(testUser{}))
+	assert.Equal(t, []Field{
+		{Name: "name", Index: []int{3}},
+		{Name: "nickname", Index: []int{4}, OmitEmpty: true},
+		{Name: "id", Index: []int{0, 0}},
+		{Name: "by", Index: []int{1, 1}},
+	}, fields)
+
+	// cached
+	assert.Equal(t, fields, Fields(reflect.TypeOf(testUser{})))
+}
+
+func TestFieldByIndex(t *testing.T) {
+	u := testUser{testBase: testBase{ID: 5}}
+	v := reflect.ValueOf(u)
+
+	id, ok := FieldByIndex(v, []int{0, 0})
+	assert.True(t, ok)
+	assert.Equal(t, 5, id.Interface())
+
+	_, ok = FieldByIndex(v, []int{1, 1})
+	assert.False(t, ok, "nil embedded pointer")
+
+	u.testAudit = &testAudit{By: "bob"}
+	by, ok := FieldByIndex(reflect.ValueOf(u), []int{1, 1})
+	assert.True(t, ok)
+	assert.Equal(t, "bob", by.Interface())
+}
+
+type TestExportedAudit struct {
+	By string `db:"by"`
+}
+
+type testAllocUser struct {
+	*TestExportedAudit
+	*testAudit
+}
+
+func TestFieldByIndexAlloc(t *testing.T) {
+	u := testAllocUser{}
+	v := reflect.ValueOf(&u).Elem()
+
+	by, ok := FieldByIndexAlloc(v, []int{0, 0})
+	assert.True(t, ok)
+	by.SetString("bob")
+	assert.Equal(t, "bob", u.TestExportedAudit.By)
+
+	_, ok = FieldByIndexAlloc(v, []int{1, 1})
+	assert.False(t, ok, "unexported embedded pointers cannot be allocated")
+}
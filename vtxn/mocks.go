@@ -24,14 +24,14 @@ type TxOptionerMock struct {
 	mock.Mock
 }
 
-func (t TxOptionerMock) IsolationLevel() sql.IsolationLevel {
+func (t *TxOptionerMock) IsolationLevel() sql.IsolationLevel {
 	a := t.Called()
 	return a.Get(0).(sql.IsolationLevel)
 }
 func (t *TxOptionerMock) SetIsolationLevel(x sql.IsolationLevel) {
 	t.Called(x)
 }
-func (t TxOptionerMock) ReadOnly() bool {
+func (t *TxOptionerMock) ReadOnly() bool {
 	a := t.Called()
 	return a.Bool(0)
 }